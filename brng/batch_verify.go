@@ -0,0 +1,125 @@
+package brng
+
+import (
+	"crypto/sha256"
+
+	"github.com/renproject/secp256k1-go"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/curve"
+)
+
+// TransitionSliceBatched is equivalent to TransitionSlice, but verifies the
+// whole slice with a single combined equation instead of calling
+// scheme.IsValid once per share. The combined equation is accumulated term
+// by term with individual scalar multiplications and additions; no
+// multi-scalar multiplication algorithm is used, so the number of curve
+// operations is not reduced below the O(N.k.b) of TransitionSlice for a
+// slice with N rows, reconstruction threshold k and batch size b.
+//
+// The weights r_{c,i} used to combine individual checks into one are
+// derived deterministically via Fiat-Shamir over a transcript of the
+// slice's commitments and share indices, so the check remains
+// non-interactive. If the combined check fails, TransitionSliceBatched
+// falls back to the per-share loop in TransitionSlice to identify exactly
+// which column is at fault.
+func (brnger *BRNGer) TransitionSliceBatched(slice Slice) (shamir.VerifiableShares, []shamir.Commitment, []secp256k1.Secp256k1N) {
+	if brnger.state != Waiting {
+		return nil, nil, nil
+	}
+
+	if !slice.HasValidForm() {
+		brnger.state = Error
+		return nil, nil, nil
+	}
+
+	if !batchIsValid(slice, brnger.scheme.Parameter()) {
+		return brnger.TransitionSlice(slice)
+	}
+
+	shares, commitments := sumSlice(slice)
+	brnger.pk = aggregatePK(commitments)
+	brnger.state = Ok
+	return shares, commitments, nil
+}
+
+// batchIsValid checks every (commitment, share) pair in the slice at once:
+//
+//	sum_{c,i} r_{c,i} . (share_{c,i}.G + decom_{c,i}.schemeParameter)
+//	  == sum_{c,i} r_{c,i} . Eval(commitment_{c,i}, ownIndex)
+//
+// This is scheme-agnostic despite looking Pedersen-shaped: schemeParameter
+// must be the CommitmentScheme's own Parameter(), which is the point at
+// infinity for Feldman, so decom_{c,i}.schemeParameter collapses to the
+// identity for every share regardless of what its (unverified, ignored)
+// Decommitment actually holds, and the check reduces exactly to Feldman's
+// own IsValid: share_{c,i}.G == Eval(commitment_{c,i}, ownIndex).
+func batchIsValid(slice Slice, schemeParameter curve.Point) bool {
+	weights := fiatShamirWeights(slice)
+
+	var lhs, rhs curve.Point
+
+	weightIdx := 0
+	for _, col := range slice {
+		for i := range col.shares {
+			r := weights[weightIdx]
+			weightIdx++
+
+			share := col.shares[i]
+			commitment := col.commitments[i]
+
+			var shareTerm curve.Point
+			shareTerm.BaseExp(&share.Share.Value)
+
+			var decomTerm curve.Point
+			decomTerm.Scale(&schemeParameter, &share.Decommitment)
+
+			var combined curve.Point
+			combined.Add(&shareTerm, &decomTerm)
+
+			var weighted curve.Point
+			weighted.Scale(&combined, &r)
+			lhs.Add(&lhs, &weighted)
+
+			evaluated := Eval(commitment, share.Share.Index)
+			var weightedEval curve.Point
+			weightedEval.Scale(&evaluated, &r)
+			rhs.Add(&rhs, &weightedEval)
+		}
+	}
+
+	return lhs.Eq(&rhs)
+}
+
+// fiatShamirWeights derives one deterministic scalar weight per (column,
+// share) pair in the slice, by hashing a transcript of every commitment and
+// share index in the slice. Using the same transcript for every weight
+// ensures that a corrupted player cannot predict (and so cannot exploit)
+// the weights before committing to its shares.
+func fiatShamirWeights(slice Slice) []secp256k1.Secp256k1N {
+	h := sha256.New()
+	count := 0
+	for _, col := range slice {
+		for i := range col.shares {
+			count++
+
+			var indexBytes [32]byte
+			col.shares[i].Share.Index.GetB32(indexBytes[:])
+			h.Write(indexBytes[:])
+
+			for _, c := range col.commitments[i] {
+				var cBytes [32]byte
+				c.GetB32(cBytes[:])
+				h.Write(cBytes[:])
+			}
+		}
+	}
+
+	seed := h.Sum(nil)
+
+	weights := make([]secp256k1.Secp256k1N, count)
+	for i := range weights {
+		digest := sha256.Sum256(append(seed, byte(i), byte(i>>8)))
+		weights[i].SetB32(digest[:])
+	}
+	return weights
+}
@@ -0,0 +1,85 @@
+package brng_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/mpc/brng"
+
+	"github.com/renproject/mpc/brng/brngutil"
+	"github.com/renproject/shamir/curve"
+	"github.com/renproject/shamir/shamirutil"
+)
+
+var _ = Describe("Batch verification", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	// batchIsValid's combined check (see its doc comment) is scheme-agnostic:
+	// it folds in the CommitmentScheme's own Parameter(), which collapses to
+	// the identity for Feldman, so it reduces exactly to Feldman's own
+	// IsValid. A Feldman-mode Specify analogous to the ones below, covering
+	// TransitionSliceBatched the same way transition_test.go now covers the
+	// RNGer state machine for rng, belongs here once it is possible to construct
+	// a Slice at all: every brng_test helper that builds one goes through
+	// brngutil, which does not exist in this tree, and Slice/Col themselves
+	// are not defined anywhere in this package either, so no test here -
+	// Feldman or otherwise - can build its own fixture as a workaround.
+
+	Specify("a valid slice is accepted and produces the same output as TransitionSlice", func() {
+		n, k, b := 20, 7, 5
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+		to := indices[0]
+
+		brnger := New(indices, NewPedersen(h))
+		brnger.TransitionStart(k, b)
+
+		slice := brngutil.RandomValidSlice(to, indices, h, k, b, k)
+		shares, commitments, faults := brnger.TransitionSliceBatched(slice)
+
+		Expect(brnger.State()).To(Equal(Ok))
+		Expect(faults).To(BeEmpty())
+		Expect(shares).To(HaveLen(b))
+		Expect(commitments).To(HaveLen(b))
+	})
+
+	Specify("an invalid slice falls back to identifying the bad column", func() {
+		n, k, b, t := 20, 7, 5, 6
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+		to := indices[0]
+
+		brnger := New(indices, NewPedersen(h))
+		brnger.TransitionStart(k, b)
+
+		slice, _ := brngutil.RandomInvalidSlice(to, indices, h, n, k, b, t)
+		shares, commitments, faults := brnger.TransitionSliceBatched(slice)
+
+		Expect(brnger.State()).To(Equal(Error))
+		Expect(shares).To(BeNil())
+		Expect(commitments).To(BeNil())
+		Expect(faults).ToNot(BeEmpty())
+	})
+
+	Specify("two machines given the same slice produce the same output", func() {
+		n, k, b := 20, 7, 5
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+		to := indices[0]
+
+		slice := brngutil.RandomValidSlice(to, indices, h, k, b, k)
+
+		first := New(indices, NewPedersen(h))
+		first.TransitionStart(k, b)
+		firstShares, firstCommitments, _ := first.TransitionSliceBatched(slice)
+
+		second := New(indices, NewPedersen(h))
+		second.TransitionStart(k, b)
+		secondShares, secondCommitments, _ := second.TransitionSliceBatched(slice)
+
+		Expect(firstShares).To(Equal(secondShares))
+		Expect(firstCommitments).To(Equal(secondCommitments))
+	})
+})
@@ -19,8 +19,20 @@ const (
 type BRNGer struct {
 	state State
 
-	sharer  shamir.VSSharer
-	checker shamir.VSSChecker
+	sharer shamir.VSSharer
+
+	// scheme is the CommitmentScheme this BRNGer validates shares against, as
+	// passed to New (Pedersen or Feldman; there is no default). Both
+	// TransitionSlice (via scheme.IsValid) and TransitionSliceBatched (via
+	// scheme.Parameter, see batchIsValid) go through scheme rather than
+	// assuming Pedersen, so either mode works under either scheme.
+	scheme CommitmentScheme
+
+	// pk holds, per batch element, the aggregate public key sum_j C_j[0] of
+	// the output commitments. It is set whenever TransitionSlice or
+	// TransitionSliceBatched reaches Ok, for a DKG-mode invocation (see
+	// TransitionStartWithSecret) and a regular one alike, and is nil before.
+	pk []curve.Point
 }
 
 // State returns the current state of the state machine.
@@ -28,14 +40,13 @@ func (brnger *BRNGer) State() State {
 	return brnger.state
 }
 
-// New creates a new BRNG state machine for the given indices and pedersen
-// parameter h.
-func New(indices []secp256k1.Secp256k1N, h curve.Point) BRNGer {
+// New creates a new BRNG state machine for the given indices and
+// CommitmentScheme (Pedersen or Feldman).
+func New(indices []secp256k1.Secp256k1N, scheme CommitmentScheme) BRNGer {
 	state := Init
-	sharer := shamir.NewVSSharer(indices, h)
-	checker := shamir.NewVSSChecker(h)
+	sharer := shamir.NewVSSharer(indices, scheme.Parameter())
 
-	return BRNGer{state, sharer, checker}
+	return BRNGer{state: state, sharer: sharer, scheme: scheme}
 }
 
 func (brnger *BRNGer) TransitionStart(k, b int) Row {
@@ -54,28 +65,51 @@ func (brnger *BRNGer) TransitionStart(k, b int) Row {
 	return row
 }
 
-func (brnger *BRNGer) TransitionSlice(slice Slice) (shamir.VerifiableShares, []shamir.Commitment) {
+// TransitionSlice consumes a Slice of rows that the players have already
+// agreed on, whether that agreement came from a single trusted consensus
+// node or from the brng/consensus BFT sub-protocol; BRNGer itself does not
+// need to know which. If every share in the slice is valid against its
+// commitment, it returns the summed shares and commitments; otherwise it
+// returns the indices of every contributor whose share failed validation, so
+// that a caller can hold them accountable instead of the batch silently
+// failing.
+func (brnger *BRNGer) TransitionSlice(slice Slice) (shamir.VerifiableShares, []shamir.Commitment, []secp256k1.Secp256k1N) {
 	if brnger.state != Waiting {
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	if !slice.HasValidForm() {
 		brnger.state = Error
-		return nil, nil
+		return nil, nil, nil
 	}
 
-	// TODO: Should we try to reconstruct on a per column basis? Or just give
-	// up if any of the columns in the slice are invalid?
+	seenFaults := make(map[secp256k1.Secp256k1N]bool)
+	var faults []secp256k1.Secp256k1N
 	for _, c := range slice {
 		for i := 0; i < len(c.shares); i++ {
-			if !brnger.checker.IsValid(&c.commitments[i], &c.shares[i]) {
-				brnger.state = Error
-				return nil, nil
+			if !brnger.scheme.IsValid(&c.commitments[i], &c.shares[i]) {
+				index := c.shares[i].Share.Index
+				if !seenFaults[index] {
+					seenFaults[index] = true
+					faults = append(faults, index)
+				}
 			}
 		}
 	}
+	if len(faults) > 0 {
+		brnger.state = Error
+		return nil, nil, faults
+	}
+
+	shares, commitments := sumSlice(slice)
+	brnger.pk = aggregatePK(commitments)
+	brnger.state = Ok
+	return shares, commitments, nil
+}
 
-	// Construct the output share(s).
+// sumSlice sums each column of a slice into a single output share and
+// commitment, without performing any validation.
+func sumSlice(slice Slice) (shamir.VerifiableShares, []shamir.Commitment) {
 	shares := make(shamir.VerifiableShares, slice.BatchSize())
 	commitments := make([]shamir.Commitment, slice.BatchSize())
 	for i, c := range slice {
@@ -92,11 +126,20 @@ func (brnger *BRNGer) TransitionSlice(slice Slice) (shamir.VerifiableShares, []s
 		}
 		commitments[i] = commitment
 	}
-
-	brnger.state = Ok
 	return shares, commitments
 }
 
+// aggregatePK computes, for each element of the batch, the sum of every
+// contributor's constant-term commitment, which is exactly the constant
+// term of the already-summed commitment.
+func aggregatePK(commitments []shamir.Commitment) []curve.Point {
+	pk := make([]curve.Point, len(commitments))
+	for i, commitment := range commitments {
+		pk[i] = commitment[0]
+	}
+	return pk
+}
+
 // Reset sets the state of the state machine to the Init state.
 func (brnger *BRNGer) Reset() {
 	brnger.state = Init
@@ -54,7 +54,7 @@ var _ = Describe("BRNG", func() {
 		t := k - 1
 		indices := shamirutil.RandomIndices(n)
 		to := indices[0]
-		brnger := New(indices, h)
+		brnger := New(indices, NewPedersen(h))
 
 		return brnger, t, b, to, indices
 	}
@@ -297,6 +297,23 @@ var _ = Describe("BRNG", func() {
 	})
 
 	Context("Network (5)", func() {
+		// Crash faults (offline machines) are covered below via
+		// MessageShufflerDropper. The richer Byzantine strategies in
+		// mpcutil.Adversary (equivocation, commitment/share mismatch,
+		// rushing, collusion) are exercised directly against VSS shares in
+		// mpcutil's own test suite instead of here, and this Network (5)
+		// test does NOT yet assert that every corrupted player appears in a
+		// returned faults slice, so it does not fully exercise this
+		// package's acceptance criterion for an adversarial network. Wiring
+		// mpcutil.Adversary into this test requires a hook on
+		// brngutil.Machine that does not exist, and brngutil is not
+		// buildable in this tree at all. A hand-rolled replacement network
+		// (bypassing brngutil.Machine entirely, the way
+		// rng/reactor/network_test.go does for RNG) is not possible either:
+		// the Row/Slice/Col types TransitionSlice itself takes are not
+		// defined anywhere in this package, so no test in this file -
+		// adversarial or otherwise - can be constructed without them first
+		// existing. Revisit once both gaps are filled.
 		Specify("BRNG should function correctly in a network with offline machines", func() {
 			n = 20
 			k = 7
@@ -398,7 +415,7 @@ var _ = Describe("BRNG", func() {
 
 			for i := 0; i < trials; i++ {
 				buf.Reset()
-				brnger1 := New(indices, h)
+				brnger1 := New(indices, NewPedersen(h))
 				m, err := brnger1.Marshal(buf, brnger1.SizeHint())
 				Expect(err).ToNot(HaveOccurred())
 				Expect(m).To(Equal(0))
@@ -415,7 +432,7 @@ var _ = Describe("BRNG", func() {
 		It("should fail when marshalling without enough remaining bytes", func() {
 			buf := bytes.NewBuffer([]byte{})
 			indices := shamirutil.RandomIndices(n)
-			brnger := New(indices, h)
+			brnger := New(indices, NewPedersen(h))
 
 			for i := 0; i < brnger.SizeHint(); i++ {
 				buf.Reset()
@@ -426,7 +443,7 @@ var _ = Describe("BRNG", func() {
 
 		It("should fail when marshalling without enough remaining bytes", func() {
 			indices := shamirutil.RandomIndices(n)
-			brnger1 := New(indices, h)
+			brnger1 := New(indices, NewPedersen(h))
 			bs, _ := surge.ToBinary(brnger1)
 
 			var brnger2 BRNGer
@@ -442,7 +459,7 @@ var _ = Describe("BRNG", func() {
 	Context("Getters", func() {
 		It("should return the number of indices for the instance", func() {
 			indices := shamirutil.RandomIndices(n)
-			brnger := New(indices, h)
+			brnger := New(indices, NewPedersen(h))
 
 			Expect(brnger.N()).To(Equal(len(indices)))
 		})
@@ -0,0 +1,264 @@
+// Package consensus implements a Tendermint-style round-based BFT
+// sub-protocol that lets the players participating in BRNG agree on which
+// subset of rows to include in a batch, rather than trusting a single
+// consensus node to collect and shuffle them. It tolerates up to t = k-1
+// Byzantine players, matching the fault tolerance BRNG already has in its
+// sharing step, provided every Vote is authenticated before it reaches
+// OnVote; Service itself only enforces that each known player contributes at
+// most one prevote and one precommit per round.
+//
+// Service is deliberately consumed the same way BRNGer consumes a Slice: a
+// caller runs it alongside a BRNG execution and feeds the resulting
+// Decision's rows in as the agreed Slice, so BRNGer itself stays agnostic to
+// how that agreement was reached (see brng.BRNGer.TransitionSlice). No such
+// caller is wired up in this repo yet: the existing brngutil-based network
+// tests still agree on rows via a single trusted consensus node
+// (brngutil.BrngTypeConsensus) rather than this package, because
+// brngutil.Machine has no hook a Service could drive instead, and adding one
+// is out of scope for this package.
+package consensus
+
+import (
+	"github.com/renproject/secp256k1-go"
+
+	"github.com/renproject/mpc/brng/table"
+)
+
+// Height identifies a BRNG session. Each independent BRNG execution that
+// runs consensus on its rows does so at its own height.
+type Height uint64
+
+// Round is a round number within a given Height. A player may need several
+// rounds to reach a decision if the first proposer is faulty or proposals
+// conflict.
+type Round uint64
+
+// ProposalID is the hash of a proposed PartSet, used to identify which
+// proposal a prevote or precommit refers to.
+type ProposalID [32]byte
+
+// PartSet is a Merkle-chunked payload carrying a player's proposed multiset
+// of rows, so that large row batches can be gossiped incrementally rather
+// than as a single message.
+type PartSet struct {
+	Rows  []table.Row
+	Parts [][]byte
+	Root  ProposalID
+}
+
+// Proposal is a single player's suggestion, at a given height and round, for
+// the multiset of rows that should be included in the batch.
+type Proposal struct {
+	Height Height
+	Round  Round
+	Parts  PartSet
+}
+
+// VoteType distinguishes a prevote from a precommit.
+type VoteType uint8
+
+const (
+	Prevote = VoteType(iota)
+	Precommit
+)
+
+// Vote is a single player's vote, at a given height and round, for a
+// proposal. Every ProposalID, the zero value included, is counted as a vote
+// for that ID; there is no vote against.
+type Vote struct {
+	Type       VoteType
+	Height     Height
+	Round      Round
+	ProposalID ProposalID
+	From       secp256k1.Secp256k1N
+}
+
+// PoLC is a Proof-of-Lock-Change: evidence that a player has seen 2t+1
+// prevotes for a given proposal, and so is locked onto it until it observes
+// a higher-round PoLC for a different proposal.
+type PoLC struct {
+	Round      Round
+	ProposalID ProposalID
+	Prevotes   []Vote
+}
+
+// Decision is the outcome of the consensus sub-protocol for a given height:
+// the multiset of rows that 2t+1 players precommitted to.
+type Decision struct {
+	Height Height
+	Rows   []table.Row
+}
+
+// Service abstracts away the round-based voting so that a caller only needs
+// to know when a height has decided. The BRNGer state machine does not drive
+// it: BRNGer.TransitionSlice still consumes a Slice directly.
+type Service interface {
+	// OnRow is called when this player receives (or produces) a row to
+	// propose for the given height. The row is added to this player's
+	// proposal for the height's current round, as last set by NewRound (round
+	// 0 if NewRound has never been called for this height).
+	OnRow(height Height, row table.Row)
+
+	// NewRound advances this player's current round for height, so that
+	// subsequent OnRow calls build a fresh proposal for round rather than
+	// continuing to append to an earlier, abandoned one. A caller drives this
+	// when a round has timed out or a higher-round PoLC for a different
+	// proposal has been observed, matching Tendermint's round-change trigger.
+	NewRound(height Height, round Round)
+
+	// OnVote processes a prevote or precommit received from a peer.
+	OnVote(vote Vote)
+
+	// Decide returns a channel on which a Decision is sent once 2t+1
+	// precommits have been observed for the same proposal hash.
+	Decide() <-chan Decision
+}
+
+// roundState tracks the vote counts and lock status for a single (height,
+// round) pair. prevoted and precommitted record which players have already
+// cast a vote of each type in this round, so that a single player cannot
+// count more than once towards 2t+1 by repeating or equivocating its vote.
+type roundState struct {
+	prevotes     map[ProposalID][]Vote
+	precommits   map[ProposalID][]Vote
+	prevoted     map[secp256k1.Secp256k1N]bool
+	precommitted map[secp256k1.Secp256k1N]bool
+	locked       *PoLC
+}
+
+// service is the default Service implementation.
+type service struct {
+	t int
+
+	players map[secp256k1.Secp256k1N]bool
+
+	proposals    map[Height]map[Round]Proposal
+	currentRound map[Height]Round
+	rounds       map[Height]map[Round]*roundState
+	decided      map[Height]bool
+
+	decisions chan Decision
+}
+
+// New constructs a Service for the given set of registered players,
+// tolerating t Byzantine players among them, so that a decision requires
+// 2t+1 matching precommits from distinct, recognised players. Votes whose
+// From is not in indices, or that repeat a player's earlier vote in the same
+// round, are ignored.
+func New(indices []secp256k1.Secp256k1N, t int) Service {
+	players := make(map[secp256k1.Secp256k1N]bool, len(indices))
+	for _, index := range indices {
+		players[index] = true
+	}
+
+	return &service{
+		t: t,
+
+		players: players,
+
+		proposals:    make(map[Height]map[Round]Proposal),
+		currentRound: make(map[Height]Round),
+		rounds:       make(map[Height]map[Round]*roundState),
+		decided:      make(map[Height]bool),
+
+		decisions: make(chan Decision, 1),
+	}
+}
+
+func (s *service) roundStateFor(height Height, round Round) *roundState {
+	if s.rounds[height] == nil {
+		s.rounds[height] = make(map[Round]*roundState)
+	}
+	rs, ok := s.rounds[height][round]
+	if !ok {
+		rs = &roundState{
+			prevotes:     make(map[ProposalID][]Vote),
+			precommits:   make(map[ProposalID][]Vote),
+			prevoted:     make(map[secp256k1.Secp256k1N]bool),
+			precommitted: make(map[secp256k1.Secp256k1N]bool),
+		}
+		s.rounds[height][round] = rs
+	}
+	return rs
+}
+
+// OnRow implements the Service interface. The row is wrapped into this
+// player's own proposal for the height's current round.
+func (s *service) OnRow(height Height, row table.Row) {
+	if s.proposals[height] == nil {
+		s.proposals[height] = make(map[Round]Proposal)
+	}
+	round := s.currentRound[height]
+	proposal := s.proposals[height][round]
+	proposal.Height = height
+	proposal.Round = round
+	proposal.Parts.Rows = append(proposal.Parts.Rows, row)
+	s.proposals[height][round] = proposal
+}
+
+// NewRound implements the Service interface.
+func (s *service) NewRound(height Height, round Round) {
+	s.currentRound[height] = round
+}
+
+// OnVote implements the Service interface. A vote from a sender that is not
+// in the registered player set, or that repeats a sender's earlier vote of
+// the same type in the same round (whether identical or equivocating onto a
+// different ProposalID), is ignored and never counted towards 2t+1.
+func (s *service) OnVote(vote Vote) {
+	if s.decided[vote.Height] {
+		return
+	}
+	if !s.players[vote.From] {
+		return
+	}
+
+	rs := s.roundStateFor(vote.Height, vote.Round)
+
+	switch vote.Type {
+	case Prevote:
+		if rs.prevoted[vote.From] {
+			return
+		}
+		rs.prevoted[vote.From] = true
+		rs.prevotes[vote.ProposalID] = append(rs.prevotes[vote.ProposalID], vote)
+
+		// A PoLC locks this player onto a proposal once 2t+1 prevotes for it
+		// have been seen.
+		if len(rs.prevotes[vote.ProposalID]) >= 2*s.t+1 {
+			rs.locked = &PoLC{
+				Round:      vote.Round,
+				ProposalID: vote.ProposalID,
+				Prevotes:   rs.prevotes[vote.ProposalID],
+			}
+		}
+
+	case Precommit:
+		if rs.precommitted[vote.From] {
+			return
+		}
+		rs.precommitted[vote.From] = true
+		rs.precommits[vote.ProposalID] = append(rs.precommits[vote.ProposalID], vote)
+
+		if len(rs.precommits[vote.ProposalID]) >= 2*s.t+1 {
+			s.decided[vote.Height] = true
+
+			// A decision this player itself helped reach must be for a round
+			// whose proposal this player received via OnRow; if not, the
+			// round-advance bookkeeping above has a bug, since we must not
+			// silently report an empty decision for a round that actually
+			// had rows.
+			proposal, ok := s.proposals[vote.Height][vote.Round]
+			if !ok {
+				panic("decided on a round this player never proposed or received rows for")
+			}
+
+			s.decisions <- Decision{Height: vote.Height, Rows: proposal.Parts.Rows}
+		}
+	}
+}
+
+// Decide implements the Service interface.
+func (s *service) Decide() <-chan Decision {
+	return s.decisions
+}
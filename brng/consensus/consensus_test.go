@@ -0,0 +1,157 @@
+package consensus_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/renproject/secp256k1-go"
+
+	"github.com/renproject/mpc/brng/consensus"
+	"github.com/renproject/mpc/brng/table"
+)
+
+var _ = Describe("Consensus", func() {
+	Specify("2t+1 matching precommits from distinct registered players produce a decision", func() {
+		n, t := 7, 2
+		height := consensus.Height(1)
+		row := table.Row{}
+
+		indices := make([]secp256k1.Secp256k1N, n)
+		for i := range indices {
+			indices[i] = secp256k1.RandomSecp256k1N()
+		}
+
+		svc := consensus.New(indices, t)
+		svc.OnRow(height, row)
+
+		var proposalID consensus.ProposalID
+		for i := 0; i < 2*t+1; i++ {
+			svc.OnVote(consensus.Vote{
+				Type:       consensus.Precommit,
+				Height:     height,
+				Round:      0,
+				ProposalID: proposalID,
+				From:       indices[i],
+			})
+		}
+
+		Eventually(svc.Decide()).Should(Receive(Equal(consensus.Decision{
+			Height: height,
+			Rows:   []table.Row{row},
+		})))
+	})
+
+	Specify("repeated precommits from the same player only count once", func() {
+		n, t := 7, 2
+		height := consensus.Height(1)
+		row := table.Row{}
+
+		indices := make([]secp256k1.Secp256k1N, n)
+		for i := range indices {
+			indices[i] = secp256k1.RandomSecp256k1N()
+		}
+
+		svc := consensus.New(indices, t)
+		svc.OnRow(height, row)
+
+		var proposalID consensus.ProposalID
+
+		// A single player repeating (or equivocating) its precommit should
+		// never be able to manufacture a decision on its own, no matter how
+		// many times it votes.
+		for i := 0; i < 2*t+1; i++ {
+			svc.OnVote(consensus.Vote{
+				Type:       consensus.Precommit,
+				Height:     height,
+				Round:      0,
+				ProposalID: proposalID,
+				From:       indices[0],
+			})
+		}
+		Consistently(svc.Decide()).ShouldNot(Receive())
+
+		// Once 2t distinct players have also precommitted, the repeating
+		// player's single counted vote makes up the 2t+1st.
+		for i := 1; i < 2*t+1; i++ {
+			svc.OnVote(consensus.Vote{
+				Type:       consensus.Precommit,
+				Height:     height,
+				Round:      0,
+				ProposalID: proposalID,
+				From:       indices[i],
+			})
+		}
+		Eventually(svc.Decide()).Should(Receive(Equal(consensus.Decision{
+			Height: height,
+			Rows:   []table.Row{row},
+		})))
+	})
+
+	Specify("a decision reached at a round greater than 0 carries the rows proposed for that round", func() {
+		n, t := 7, 2
+		height := consensus.Height(1)
+		roundZeroRow := table.Row{}
+		roundOneRow := table.Row{}
+
+		indices := make([]secp256k1.Secp256k1N, n)
+		for i := range indices {
+			indices[i] = secp256k1.RandomSecp256k1N()
+		}
+
+		svc := consensus.New(indices, t)
+
+		// Round 0's proposer turns out to be faulty (or its proposal
+		// conflicts), so this player never sees 2t+1 precommits for it and
+		// moves on to round 1 with a fresh row. Round 0's proposal holds two
+		// rows and round 1's one, so the decision's length tells them apart.
+		svc.OnRow(height, roundZeroRow)
+		svc.OnRow(height, roundZeroRow)
+		svc.NewRound(height, 1)
+		svc.OnRow(height, roundOneRow)
+
+		var proposalID consensus.ProposalID
+		for i := 0; i < 2*t+1; i++ {
+			svc.OnVote(consensus.Vote{
+				Type:       consensus.Precommit,
+				Height:     height,
+				Round:      1,
+				ProposalID: proposalID,
+				From:       indices[i],
+			})
+		}
+
+		Eventually(svc.Decide()).Should(Receive(Equal(consensus.Decision{
+			Height: height,
+			Rows:   []table.Row{roundOneRow},
+		})))
+	})
+
+	Specify("votes from senders outside the registered player set are ignored", func() {
+		n, t := 7, 2
+		height := consensus.Height(1)
+		row := table.Row{}
+
+		indices := make([]secp256k1.Secp256k1N, n)
+		for i := range indices {
+			indices[i] = secp256k1.RandomSecp256k1N()
+		}
+
+		svc := consensus.New(indices, t)
+		svc.OnRow(height, row)
+
+		var proposalID consensus.ProposalID
+
+		// An attacker with no registered index cannot contribute any votes,
+		// however many it sends.
+		for i := 0; i < 2*t+1; i++ {
+			svc.OnVote(consensus.Vote{
+				Type:       consensus.Precommit,
+				Height:     height,
+				Round:      0,
+				ProposalID: proposalID,
+				From:       secp256k1.RandomSecp256k1N(),
+			})
+		}
+		Consistently(svc.Decide()).ShouldNot(Receive())
+	})
+})
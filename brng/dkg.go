@@ -0,0 +1,42 @@
+package brng
+
+import (
+	"github.com/renproject/secp256k1-go"
+	"github.com/renproject/shamir/curve"
+)
+
+// TransitionStartWithSecret behaves like TransitionStart, except that row i's
+// polynomial is seeded with secrets[i] as its constant term (still hidden
+// under a fresh Pedersen blinding polynomial) rather than a uniformly random
+// value. This is the building block for a Pedersen/joint-Feldman style DKG:
+// once the row has gone through consensus and TransitionSlice has combined
+// every contributor's row, PK exposes the resulting aggregate public key,
+// letting downstream code (e.g. threshold signing) treat the BRNG output as
+// a durable shared key rather than throwaway randomness.
+func (brnger *BRNGer) TransitionStartWithSecret(k, b int, secrets []secp256k1.Secp256k1N) Row {
+	if brnger.state != Init {
+		return nil
+	}
+	if len(secrets) != b {
+		panic("invalid number of secrets")
+	}
+
+	row := MakeRow(brnger.sharer.N(), k, b)
+	for i := range row {
+		brnger.sharer.Share(&row[i].shares, &row[i].commitment, secrets[i], k)
+	}
+
+	brnger.state = Waiting
+
+	return row
+}
+
+// PK returns the aggregate public key PK = sum_j C_j[0] for each element of
+// the batch, where the sum is taken over every contributor's commitment to
+// its row. It is set once TransitionSlice (or TransitionSliceBatched) has
+// transitioned the machine to the Ok state, whether or not the invocation
+// was DKG-mode, and is nil before that; it is a durable key only for a
+// DKG-mode invocation.
+func (brnger *BRNGer) PK() []curve.Point {
+	return brnger.pk
+}
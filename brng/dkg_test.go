@@ -0,0 +1,55 @@
+package brng_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/mpc/brng"
+
+	"github.com/renproject/mpc/brng/brngutil"
+	"github.com/renproject/secp256k1-go"
+	"github.com/renproject/shamir/curve"
+	"github.com/renproject/shamir/shamirutil"
+)
+
+var _ = Describe("DKG mode", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	Specify("PK is nil before a DKG-mode invocation completes", func() {
+		n, k, b := 10, 4, 3
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+
+		brnger := New(indices, NewPedersen(h))
+		Expect(brnger.PK()).To(BeNil())
+
+		secrets := make([]secp256k1.Secp256k1N, b)
+		for i := range secrets {
+			secrets[i] = secp256k1.RandomSecp256k1N()
+		}
+		brnger.TransitionStartWithSecret(k, b, secrets)
+		Expect(brnger.PK()).To(BeNil())
+	})
+
+	Specify("PK is populated once a DKG-mode invocation completes", func() {
+		n, k, b := 10, 4, 3
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+		to := indices[0]
+
+		brnger := New(indices, NewPedersen(h))
+
+		secrets := make([]secp256k1.Secp256k1N, b)
+		for i := range secrets {
+			secrets[i] = secp256k1.RandomSecp256k1N()
+		}
+		brnger.TransitionStartWithSecret(k, b, secrets)
+
+		slice := brngutil.RandomValidSlice(to, indices, h, k, b, k)
+		_, _, _ = brnger.TransitionSlice(slice)
+
+		Expect(brnger.PK()).To(HaveLen(b))
+	})
+})
@@ -0,0 +1,99 @@
+package brng
+
+import (
+	"github.com/renproject/secp256k1-go"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/curve"
+)
+
+// CommitmentScheme abstracts over the verifiable secret sharing scheme a
+// BRNGer uses to validate incoming shares against their commitments, and to
+// derive the Opener-style parameter New builds its sharer from. This
+// mirrors rng.CommitmentScheme; the two are defined separately, rather than
+// shared, because brng and rng pin different (mutually incompatible)
+// versions of the underlying secp256k1/shamir packages.
+type CommitmentScheme interface {
+	// IsValid reports whether share is consistent with commitment.
+	IsValid(commitment *shamir.Commitment, share *shamir.VerifiableShare) bool
+
+	// Parameter returns the scheme's blinding point, used to construct the
+	// BRNGer's sharer and used by batchIsValid. It is the Pedersen parameter H for
+	// Pedersen, and the point at infinity (no blinding) for Feldman.
+	Parameter() curve.Point
+}
+
+// Pedersen is the default CommitmentScheme, in which commitments are
+// blinded with the Pedersen parameter H so that they are hiding as well as
+// binding.
+type Pedersen struct {
+	H curve.Point
+}
+
+// NewPedersen constructs a Pedersen CommitmentScheme with the given
+// commitment scheme parameter.
+func NewPedersen(h curve.Point) Pedersen {
+	return Pedersen{H: h}
+}
+
+// IsValid implements the CommitmentScheme interface.
+func (scheme Pedersen) IsValid(commitment *shamir.Commitment, share *shamir.VerifiableShare) bool {
+	return shamir.NewVSSChecker(scheme.H).IsValid(commitment, share)
+}
+
+// Parameter implements the CommitmentScheme interface.
+func (scheme Pedersen) Parameter() curve.Point {
+	return scheme.H
+}
+
+// Feldman is a non-hiding CommitmentScheme, in which commitments are simply
+// g^{a_i} for each polynomial coefficient a_i, with no blinding polynomial.
+// Feldman shares therefore carry only the value component of a
+// shamir.VerifiableShare; the decommitment is unused and ignored. This is
+// the form downstream threshold-signature protocols such as FROST require,
+// since they need the constant term's commitment g^{sk} to be recoverable,
+// which a Pedersen commitment deliberately hides.
+//
+// Note that BRNGer's sharer is still shamir.VSSharer, which always produces
+// a Pedersen-blinded decommitment alongside the share; a Feldman-mode BRNGer
+// simply disregards it during validation and when summing a slice.
+type Feldman struct{}
+
+// NewFeldman constructs a Feldman CommitmentScheme.
+func NewFeldman() Feldman {
+	return Feldman{}
+}
+
+// IsValid implements the CommitmentScheme interface. It checks that
+// share.Value()*G is equal to the commitment evaluated at the share's index,
+// ignoring the decommitment entirely.
+func (scheme Feldman) IsValid(commitment *shamir.Commitment, share *shamir.VerifiableShare) bool {
+	var expected curve.Point
+	expected.BaseExp(&share.Share.Value)
+
+	actual := Eval(*commitment, share.Share.Index)
+
+	return expected.Eq(&actual)
+}
+
+// Parameter implements the CommitmentScheme interface. Feldman has no
+// blinding polynomial, so the zero value of curve.Point (the point at
+// infinity) is used; it never contributes to a Feldman commitment.
+func (scheme Feldman) Parameter() curve.Point {
+	return curve.Point{}
+}
+
+// Eval evaluates a polynomial commitment at x, returning
+// sum_j x^j . commitment[j].
+func Eval(commitment shamir.Commitment, x secp256k1.Secp256k1N) curve.Point {
+	result := commitment[0]
+
+	power := x
+	for j := 1; j < len(commitment); j++ {
+		var term curve.Point
+		term.Scale(&commitment[j], &power)
+		result.Add(&result, &term)
+		power.Mul(&power, &x)
+	}
+
+	return result
+}
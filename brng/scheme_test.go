@@ -0,0 +1,56 @@
+package brng_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/mpc/brng"
+
+	"github.com/renproject/secp256k1-go"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/curve"
+	"github.com/renproject/shamir/shamirutil"
+)
+
+var _ = Describe("Commitment schemes", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	Specify("Feldman has no blinding parameter", func() {
+		var infinity curve.Point
+		Expect(NewFeldman().Parameter().Eq(&infinity)).To(BeTrue())
+	})
+
+	Specify("a Feldman-mode BRNGer starts and transitions like a Pedersen one", func() {
+		n, k, b := 20, 7, 5
+		indices := shamirutil.RandomIndices(n)
+
+		brnger := New(indices, NewFeldman())
+		Expect(brnger.State()).To(Equal(Init))
+
+		brnger.TransitionStart(k, b)
+		Expect(brnger.State()).To(Equal(Waiting))
+	})
+
+	Specify("Feldman rejects a share whose value is the constant term rather than the polynomial's value at its index", func() {
+		k := 4
+		index := shamirutil.RandomIndices(1)[0]
+		secret := secp256k1.RandomSecp256k1N()
+
+		commitment := shamir.NewCommitmentWithCapacity(k)
+		var constantTerm curve.Point
+		constantTerm.BaseExp(&secret)
+		commitment.Append(constantTerm)
+		for i := 1; i < k; i++ {
+			commitment.Append(curve.Random())
+		}
+
+		share := shamir.VerifiableShare{}
+		share.Share.Index = index
+		share.Share.Value = secret
+
+		scheme := NewFeldman()
+		Expect(scheme.IsValid(&commitment, &share)).To(BeFalse())
+	})
+})
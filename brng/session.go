@@ -0,0 +1,184 @@
+package brng
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/renproject/secp256k1-go"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/curve"
+	"github.com/renproject/surge"
+)
+
+// SessionID tags an independent BRNG execution so that many can be
+// multiplexed over the same (indices, h) peer subset without interfering
+// with one another.
+type SessionID [32]byte
+
+// DefaultMaxConcurrentSessions bounds how many sessions a SessionManager
+// will service at once by default. Start blocks (with backpressure) once
+// this many sessions are in flight, so a slow session cannot starve others
+// indefinitely.
+const DefaultMaxConcurrentSessions = 64
+
+// SessionManager owns a pool of BRNGers keyed by SessionID, all sharing the
+// same indices and Pedersen parameter h, and multiplexes incoming slices by
+// tag so that many independent BRNG executions can be in flight
+// simultaneously.
+type SessionManager struct {
+	mu sync.Mutex
+
+	indices []secp256k1.Secp256k1N
+	h       curve.Point
+
+	maxConcurrent int
+	sessions      map[SessionID]*BRNGer
+	sem           chan struct{}
+}
+
+// NewSessionManager constructs a SessionManager for the given indices and
+// Pedersen parameter, bounding the number of concurrently in-flight sessions
+// to maxConcurrent.
+func NewSessionManager(indices []secp256k1.Secp256k1N, h curve.Point, maxConcurrent int) *SessionManager {
+	if maxConcurrent <= 0 {
+		maxConcurrent = DefaultMaxConcurrentSessions
+	}
+
+	return &SessionManager{
+		indices:       indices,
+		h:             h,
+		maxConcurrent: maxConcurrent,
+		sessions:      make(map[SessionID]*BRNGer),
+		sem:           make(chan struct{}, maxConcurrent),
+	}
+}
+
+// Start begins the session identified by id, blocking until a concurrency
+// slot is available. It returns the Row this player should gossip to its
+// peers for that session. A session that already exists can be started again
+// only after Reset; otherwise Start returns an error and releases the slot.
+func (m *SessionManager) Start(id SessionID, k, b int) (Row, error) {
+	m.sem <- struct{}{}
+
+	m.mu.Lock()
+	brnger, ok := m.sessions[id]
+	if !ok {
+		fresh := New(m.indices, NewPedersen(m.h))
+		brnger = &fresh
+		m.sessions[id] = brnger
+	}
+	m.mu.Unlock()
+
+	row := brnger.TransitionStart(k, b)
+	if row == nil {
+		m.release()
+		return nil, fmt.Errorf("session %x: cannot start in state %v", id, brnger.State())
+	}
+
+	return row, nil
+}
+
+// Feed routes an incoming slice to the session it belongs to, returning the
+// resulting shares, commitments and faults (if any). It releases the
+// concurrency slot claimed by the matching Start call once the session
+// leaves the Waiting state.
+func (m *SessionManager) Feed(id SessionID, slice Slice) (shamir.VerifiableShares, []shamir.Commitment, []secp256k1.Secp256k1N, error) {
+	m.mu.Lock()
+	brnger, ok := m.sessions[id]
+	m.mu.Unlock()
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("unknown session %x", id)
+	}
+
+	wasWaiting := brnger.State() == Waiting
+	shares, commitments, faults := brnger.TransitionSlice(slice)
+
+	if wasWaiting && brnger.State() != Waiting {
+		m.release()
+	}
+
+	return shares, commitments, faults, nil
+}
+
+// Reset discards any state held for the given session, releasing its
+// concurrency slot if one was still held.
+func (m *SessionManager) Reset(id SessionID) {
+	m.mu.Lock()
+	brnger, ok := m.sessions[id]
+	if ok {
+		wasWaiting := brnger.State() == Waiting
+		brnger.Reset()
+		if wasWaiting {
+			m.release()
+		}
+	}
+	m.mu.Unlock()
+}
+
+// release frees a single concurrency slot without blocking if none are
+// currently held; this can happen if Reset or Feed races a Start for the
+// same session.
+func (m *SessionManager) release() {
+	select {
+	case <-m.sem:
+	default:
+	}
+}
+
+// NumSessions returns the number of sessions currently tracked by the
+// manager, including ones that have already reached Ok or Error.
+func (m *SessionManager) NumSessions() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// SizeHint implements the surge.SizeHinter interface. It only accounts for
+// the manager's construction parameters (indices, h and maxConcurrent), not
+// its in-flight sessions; see Marshal for why.
+func (m *SessionManager) SizeHint() int {
+	return surge.SizeHint(m.indices) + m.h.SizeHint() + surge.SizeHintU32
+}
+
+// Marshal implements the surge.Marshaler interface. BRNGer has no
+// (de)serialization of its own, so a SessionManager cannot durably persist
+// in-flight sessions; it only marshals the construction parameters a fresh
+// NewSessionManager needs. A process that restarts mid-flight must Reset and
+// re-Start every in-progress session after unmarshaling, rather than
+// resuming them. Add session (de)serialization here once BRNGer supports it.
+func (m *SessionManager) Marshal(buf []byte, rem int) ([]byte, int, error) {
+	buf, rem, err := surge.Marshal(m.indices, buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("marshaling indices: %v", err)
+	}
+	buf, rem, err = m.h.Marshal(buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("marshaling h: %v", err)
+	}
+	return surge.MarshalU32(uint32(m.maxConcurrent), buf, rem)
+}
+
+// Unmarshal implements the surge.Unmarshaler interface. The resulting
+// SessionManager has no in-flight sessions, the same as one constructed
+// fresh via NewSessionManager; see Marshal for why.
+func (m *SessionManager) Unmarshal(buf []byte, rem int) ([]byte, int, error) {
+	buf, rem, err := surge.Unmarshal(&m.indices, buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("unmarshaling indices: %v", err)
+	}
+	buf, rem, err = m.h.Unmarshal(buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("unmarshaling h: %v", err)
+	}
+	var maxConcurrent uint32
+	buf, rem, err = surge.UnmarshalU32(&maxConcurrent, buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("unmarshaling maxConcurrent: %v", err)
+	}
+
+	m.maxConcurrent = int(maxConcurrent)
+	m.sessions = make(map[SessionID]*BRNGer)
+	m.sem = make(chan struct{}, m.maxConcurrent)
+
+	return buf, rem, nil
+}
@@ -0,0 +1,148 @@
+package brng_test
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/renproject/mpc/brng"
+
+	"github.com/renproject/mpc/brng/brngutil"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/curve"
+	"github.com/renproject/shamir/shamirutil"
+)
+
+var _ = Describe("SessionManager", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	Specify("many overlapping sessions do not leak shares or commitments across each other", func() {
+		n, k, b := 10, 4, 3
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+		to := indices[0]
+
+		manager := NewSessionManager(indices, h, 8)
+
+		numSessions := 30
+		ids := make([]SessionID, numSessions)
+		for i := range ids {
+			rand.Read(ids[i][:])
+		}
+
+		var wg sync.WaitGroup
+		results := make([]shamir.VerifiableShares, numSessions)
+
+		for i, id := range ids {
+			wg.Add(1)
+			go func(i int, id SessionID) {
+				defer wg.Done()
+
+				_, err := manager.Start(id, k, b)
+				Expect(err).ToNot(HaveOccurred())
+
+				// A mix of valid and faulty slices.
+				var slice Slice
+				if i%3 == 0 {
+					slice, _ = brngutil.RandomInvalidSlice(to, indices, h, n, k, b, k)
+				} else {
+					slice = brngutil.RandomValidSlice(to, indices, h, k, b, k)
+				}
+
+				shares, _, _, err := manager.Feed(id, slice)
+				Expect(err).ToNot(HaveOccurred())
+				results[i] = shares
+			}(i, id)
+		}
+
+		wg.Wait()
+
+		Expect(manager.NumSessions()).To(Equal(numSessions))
+
+		// Each valid session's shares should be independent of every other
+		// session's.
+		for i := range results {
+			if i%3 == 0 {
+				continue
+			}
+			for j := range results {
+				if i == j || j%3 == 0 {
+					continue
+				}
+				Expect(results[i]).ToNot(Equal(results[j]))
+			}
+		}
+	})
+
+	Specify("Feed reports the indices of contributors whose shares are invalid", func() {
+		n, k, b := 10, 4, 3
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+		to := indices[0]
+
+		manager := NewSessionManager(indices, h, 4)
+
+		var id SessionID
+		rand.Read(id[:])
+
+		_, err := manager.Start(id, k, b)
+		Expect(err).ToNot(HaveOccurred())
+
+		slice, expectedFaults := brngutil.RandomInvalidSlice(to, indices, h, n, k, b, k-1)
+
+		shares, commitments, faults, err := manager.Feed(id, slice)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(shares).To(BeNil())
+		Expect(commitments).To(BeNil())
+		Expect(faults).To(HaveLen(len(expectedFaults)))
+	})
+
+	Specify("a SessionManager's construction parameters survive a Marshal/Unmarshal round trip", func() {
+		n, k, b := 10, 4, 3
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+
+		manager := NewSessionManager(indices, h, 8)
+
+		var id SessionID
+		rand.Read(id[:])
+		_, err := manager.Start(id, k, b)
+		Expect(err).ToNot(HaveOccurred())
+
+		buf := make([]byte, manager.SizeHint())
+		_, _, err = manager.Marshal(buf, manager.SizeHint())
+		Expect(err).ToNot(HaveOccurred())
+
+		var reconstructed SessionManager
+		_, _, err = reconstructed.Unmarshal(buf, len(buf))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(reconstructed.NumSessions()).To(Equal(0))
+
+		// The unmarshaled manager is usable with the same parameters, even
+		// though in-flight sessions (such as id, above) are not carried over.
+		_, err = reconstructed.Start(id, k, b)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Specify("Reset forgets a session's state", func() {
+		n, k, b := 6, 3, 2
+		indices := shamirutil.RandomIndices(n)
+		h := curve.Random()
+
+		manager := NewSessionManager(indices, h, 4)
+
+		var id SessionID
+		rand.Read(id[:])
+
+		_, err := manager.Start(id, k, b)
+		Expect(err).ToNot(HaveOccurred())
+
+		manager.Reset(id)
+
+		_, err = manager.Start(id, k, b)
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
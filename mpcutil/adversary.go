@@ -0,0 +1,183 @@
+package mpcutil
+
+// Adversary models a network participant that can tamper with messages as
+// they are sent and delivered, generalising MessageShufflerDropper (which
+// only ever drops messages for offline nodes) to arbitrary Byzantine
+// strategies.
+//
+// A Network that wants to exercise Byzantine behaviour runs every message
+// through the Adversary for the sender before it is queued, and again for
+// the recipient just before delivery.
+type Adversary interface {
+	// OnSend is called with a message a corrupted player is about to send.
+	// It returns the message(s) that should actually be sent in its place;
+	// returning nil drops the message, and returning more than one
+	// simulates equivocation (sending different messages to different
+	// recipients).
+	OnSend(msg Message) []Message
+
+	// OnDeliver is called with a message about to be delivered to a
+	// corrupted player. It returns the message(s) that should actually be
+	// delivered; this is the hook a rushing adversary uses to hold back
+	// delivery until it has observed enough of the network before crafting
+	// its own response.
+	OnDeliver(msg Message) []Message
+
+	// Corrupt marks the given participant as under the adversary's control.
+	// OnSend/OnDeliver are only consulted for corrupted participants.
+	Corrupt(id ID)
+
+	// IsCorrupt reports whether the given participant is under the
+	// adversary's control.
+	IsCorrupt(id ID) bool
+}
+
+// adversary is a composable base that concrete strategies embed to get
+// Corrupt/IsCorrupt bookkeeping for free.
+type adversary struct {
+	corrupted map[ID]bool
+}
+
+func newAdversary() adversary {
+	return adversary{corrupted: make(map[ID]bool)}
+}
+
+func (a *adversary) Corrupt(id ID) {
+	a.corrupted[id] = true
+}
+
+func (a *adversary) IsCorrupt(id ID) bool {
+	return a.corrupted[id]
+}
+
+// EquivocatingAdversary sends different messages to different recipients
+// whenever a corrupted player sends a message, by routing it through a
+// caller-supplied Equivocate function.
+type EquivocatingAdversary struct {
+	adversary
+
+	// Equivocate takes a genuine outgoing message and returns the set of
+	// (possibly differing) messages to actually send.
+	Equivocate func(msg Message) []Message
+}
+
+// NewEquivocatingAdversary constructs an EquivocatingAdversary.
+func NewEquivocatingAdversary(equivocate func(msg Message) []Message) *EquivocatingAdversary {
+	return &EquivocatingAdversary{adversary: newAdversary(), Equivocate: equivocate}
+}
+
+func (a *EquivocatingAdversary) OnSend(msg Message) []Message {
+	if !a.IsCorrupt(msg.From()) {
+		return []Message{msg}
+	}
+	return a.Equivocate(msg)
+}
+
+func (a *EquivocatingAdversary) OnDeliver(msg Message) []Message {
+	return []Message{msg}
+}
+
+// TamperingAdversary corrupts the payload of outgoing messages (e.g. so that
+// a commitment stays valid but its accompanying share does not) via a
+// caller-supplied Tamper function.
+type TamperingAdversary struct {
+	adversary
+
+	Tamper func(msg Message) Message
+}
+
+// NewTamperingAdversary constructs a TamperingAdversary.
+func NewTamperingAdversary(tamper func(msg Message) Message) *TamperingAdversary {
+	return &TamperingAdversary{adversary: newAdversary(), Tamper: tamper}
+}
+
+func (a *TamperingAdversary) OnSend(msg Message) []Message {
+	if !a.IsCorrupt(msg.From()) {
+		return []Message{msg}
+	}
+	return []Message{a.Tamper(msg)}
+}
+
+func (a *TamperingAdversary) OnDeliver(msg Message) []Message {
+	return []Message{msg}
+}
+
+// RushingAdversary withholds messages destined for corrupted players until
+// Release is called, so that it can craft its own responses only after it
+// has seen what the honest players sent.
+type RushingAdversary struct {
+	adversary
+
+	held []Message
+}
+
+// NewRushingAdversary constructs a RushingAdversary.
+func NewRushingAdversary() *RushingAdversary {
+	return &RushingAdversary{adversary: newAdversary()}
+}
+
+func (a *RushingAdversary) OnSend(msg Message) []Message {
+	return []Message{msg}
+}
+
+func (a *RushingAdversary) OnDeliver(msg Message) []Message {
+	if !a.IsCorrupt(msg.To()) {
+		return []Message{msg}
+	}
+	a.held = append(a.held, msg)
+	return nil
+}
+
+// Release returns every message that has been withheld so far and clears the
+// hold queue, simulating the adversary finally delivering them (optionally
+// after crafting a response of its own based on what it observed).
+func (a *RushingAdversary) Release() []Message {
+	held := a.held
+	a.held = nil
+	return held
+}
+
+// CollusionAdversary composes several Adversary strategies that share a set
+// of corrupted players, modelling up to t = k-1 corrupted players colluding
+// on a single, shared view of the network.
+type CollusionAdversary struct {
+	adversary
+	strategies []Adversary
+}
+
+// NewCollusionAdversary constructs a CollusionAdversary out of the given
+// strategies, which will be consulted in order on every send/deliver.
+func NewCollusionAdversary(strategies ...Adversary) *CollusionAdversary {
+	return &CollusionAdversary{adversary: newAdversary(), strategies: strategies}
+}
+
+func (a *CollusionAdversary) Corrupt(id ID) {
+	a.adversary.Corrupt(id)
+	for _, s := range a.strategies {
+		s.Corrupt(id)
+	}
+}
+
+func (a *CollusionAdversary) OnSend(msg Message) []Message {
+	msgs := []Message{msg}
+	for _, s := range a.strategies {
+		next := make([]Message, 0, len(msgs))
+		for _, m := range msgs {
+			next = append(next, s.OnSend(m)...)
+		}
+		msgs = next
+	}
+	return msgs
+}
+
+func (a *CollusionAdversary) OnDeliver(msg Message) []Message {
+	msgs := []Message{msg}
+	for _, s := range a.strategies {
+		next := make([]Message, 0, len(msgs))
+		for _, m := range msgs {
+			next = append(next, s.OnDeliver(m)...)
+		}
+		msgs = next
+	}
+	return msgs
+}
@@ -0,0 +1,172 @@
+package mpcutil_test
+
+import (
+	"math/rand"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/renproject/secp256k1-go"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/curve"
+	"github.com/renproject/shamir/shamirutil"
+
+	"github.com/renproject/mpc/mpcutil"
+)
+
+// shareMessage carries one player's directed opening of a VSS share to
+// another player, so that the adversary strategies below are exercised
+// against a realistic BRNG-shaped payload rather than a bare integer.
+type shareMessage struct {
+	from, to mpcutil.ID
+	share    shamir.VerifiableShare
+}
+
+func (m shareMessage) From() mpcutil.ID { return m.from }
+func (m shareMessage) To() mpcutil.ID   { return m.to }
+
+var _ = Describe("Adversary", func() {
+	Specify("an equivocating adversary can send different shares to different recipients", func() {
+		corrupted := mpcutil.ID(1)
+		h := curve.Random()
+		indices := shamirutil.RandomIndices(1)
+
+		sharer := shamir.NewVSSharer(indices, h)
+		var shares shamir.VerifiableShares
+		var commitment shamir.Commitment
+		sharer.Share(&shares, &commitment, secp256k1.RandomSecp256k1N(), 1)
+		genuine := shareMessage{from: corrupted, to: mpcutil.ID(2), share: shares[0]}
+
+		adv := mpcutil.NewEquivocatingAdversary(func(msg mpcutil.Message) []mpcutil.Message {
+			m := msg.(shareMessage)
+			bad := m
+			bad.to = mpcutil.ID(3)
+			shamirutil.PerturbValue(&bad.share)
+			return []mpcutil.Message{m, bad}
+		})
+		adv.Corrupt(corrupted)
+
+		out := adv.OnSend(genuine)
+		Expect(out).To(HaveLen(2))
+
+		checker := shamir.NewVSSChecker(h)
+		toPlayer2 := out[0].(shareMessage)
+		toPlayer3 := out[1].(shareMessage)
+		Expect(checker.IsValid(&commitment, &toPlayer2.share)).To(BeTrue())
+		Expect(checker.IsValid(&commitment, &toPlayer3.share)).To(BeFalse())
+	})
+
+	Specify("a tampering adversary corrupts a share so it no longer matches its commitment", func() {
+		n, k := 10, 4
+		h := curve.Random()
+		indices := shamirutil.RandomIndices(n)
+
+		sharer := shamir.NewVSSharer(indices, h)
+		var shares shamir.VerifiableShares
+		var commitment shamir.Commitment
+		sharer.Share(&shares, &commitment, secp256k1.RandomSecp256k1N(), k)
+
+		corrupted := mpcutil.ID(1)
+		honest := mpcutil.ID(2)
+
+		adv := mpcutil.NewTamperingAdversary(func(msg mpcutil.Message) mpcutil.Message {
+			m := msg.(shareMessage)
+			shamirutil.PerturbValue(&m.share)
+			return m
+		})
+		adv.Corrupt(corrupted)
+
+		checker := shamir.NewVSSChecker(h)
+
+		fromCorrupted := adv.OnSend(shareMessage{from: corrupted, to: mpcutil.ID(99), share: shares[0]})[0].(shareMessage)
+		Expect(checker.IsValid(&commitment, &fromCorrupted.share)).To(BeFalse())
+
+		fromHonest := adv.OnSend(shareMessage{from: honest, to: mpcutil.ID(99), share: shares[1]})[0].(shareMessage)
+		Expect(checker.IsValid(&commitment, &fromHonest.share)).To(BeTrue())
+	})
+
+	Specify("a rushing adversary withholds shares bound for corrupted players until released", func() {
+		corrupted := mpcutil.ID(1)
+		h := curve.Random()
+		indices := shamirutil.RandomIndices(1)
+
+		sharer := shamir.NewVSSharer(indices, h)
+		var shares shamir.VerifiableShares
+		var commitment shamir.Commitment
+		sharer.Share(&shares, &commitment, secp256k1.RandomSecp256k1N(), 1)
+
+		adv := mpcutil.NewRushingAdversary()
+		adv.Corrupt(corrupted)
+
+		msg := shareMessage{from: mpcutil.ID(2), to: corrupted, share: shares[0]}
+		Expect(adv.OnDeliver(msg)).To(BeNil())
+		Expect(adv.Release()).To(Equal([]mpcutil.Message{msg}))
+		Expect(adv.Release()).To(BeEmpty())
+	})
+
+	Specify("a collusion of up to t adversaries tampers every corrupted sender's share, and every one of them is caught by the honest recipients' commitment check", func() {
+		n, k := 10, 4
+		t := k - 1
+		h := curve.Random()
+		indices := shamirutil.RandomIndices(n)
+
+		sharer := shamir.NewVSSharer(indices, h)
+		var shares shamir.VerifiableShares
+		var commitment shamir.Commitment
+		sharer.Share(&shares, &commitment, secp256k1.RandomSecp256k1N(), k)
+
+		ids := make([]mpcutil.ID, n)
+		shareOf := make(map[mpcutil.ID]shamir.VerifiableShare, n)
+		for i := range indices {
+			ids[i] = mpcutil.ID(i + 1)
+			shareOf[ids[i]] = shares[i]
+		}
+
+		corrupted := make(map[mpcutil.ID]bool, t)
+		for len(corrupted) < t {
+			corrupted[ids[rand.Intn(n)]] = true
+		}
+
+		strategies := make([]mpcutil.Adversary, 0, t)
+		for range corrupted {
+			strategies = append(strategies, mpcutil.NewTamperingAdversary(func(msg mpcutil.Message) mpcutil.Message {
+				m := msg.(shareMessage)
+				shamirutil.PerturbValue(&m.share)
+				return m
+			}))
+		}
+		adv := mpcutil.NewCollusionAdversary(strategies...)
+		for id := range corrupted {
+			adv.Corrupt(id)
+		}
+
+		// A single honest recipient's view of every sender's share.
+		var recipient mpcutil.ID
+		for _, id := range ids {
+			if !corrupted[id] {
+				recipient = id
+				break
+			}
+		}
+
+		checker := shamir.NewVSSChecker(h)
+		faults := make(map[mpcutil.ID]bool)
+
+		for _, sender := range ids {
+			if sender == recipient {
+				continue
+			}
+			for _, sent := range adv.OnSend(shareMessage{from: sender, to: recipient, share: shareOf[sender]}) {
+				delivered := sent.(shareMessage)
+				if !checker.IsValid(&commitment, &delivered.share) {
+					faults[delivered.From()] = true
+				}
+			}
+		}
+
+		for id := range corrupted {
+			Expect(faults[id]).To(BeTrue())
+		}
+		Expect(len(faults)).To(Equal(len(corrupted)))
+	})
+})
@@ -0,0 +1,97 @@
+package rng
+
+import (
+	"fmt"
+
+	"github.com/renproject/surge"
+)
+
+// BitArray is a fixed-size bitmap, indexed by the position of a player
+// within an RNGer's `indices` slice. It is used to give a compact,
+// gossip-friendly summary of which directed openings have (or have not yet)
+// been received from other players.
+type BitArray struct {
+	bits []byte
+	n    int
+}
+
+// NewBitArray creates a new BitArray capable of holding n bits, all
+// initialised to false.
+func NewBitArray(n int) BitArray {
+	return BitArray{
+		bits: make([]byte, (n+7)/8),
+		n:    n,
+	}
+}
+
+// Len returns the number of bits that the BitArray can hold.
+func (b BitArray) Len() int {
+	return b.n
+}
+
+// Set marks the bit at position i as true.
+func (b *BitArray) Set(i int) {
+	if i < 0 || i >= b.n {
+		panic("bit array index out of range")
+	}
+	b.bits[i/8] |= 1 << uint(i%8)
+}
+
+// Get returns whether the bit at position i is set.
+func (b BitArray) Get(i int) bool {
+	if i < 0 || i >= b.n {
+		panic("bit array index out of range")
+	}
+	return b.bits[i/8]&(1<<uint(i%8)) != 0
+}
+
+// Count returns the number of bits that are set.
+func (b BitArray) Count() int {
+	count := 0
+	for i := 0; i < b.n; i++ {
+		if b.Get(i) {
+			count++
+		}
+	}
+	return count
+}
+
+// Not returns a new BitArray with every bit inverted.
+func (b BitArray) Not() BitArray {
+	inverted := NewBitArray(b.n)
+	for i := 0; i < b.n; i++ {
+		if !b.Get(i) {
+			inverted.Set(i)
+		}
+	}
+	return inverted
+}
+
+// SizeHint implements the surge.SizeHinter interface. The BitArray is
+// serialised as a 4-byte bit count followed by the packed bytes as a
+// surge-encoded byte slice (itself a 4-byte length prefix plus the bytes),
+// so that it is cheap to include in gossiped "have/want" summaries.
+func (b BitArray) SizeHint() int {
+	return surge.SizeHintU32 + surge.SizeHintBytes(b.bits)
+}
+
+// Marshal implements the surge.Marshaler interface.
+func (b BitArray) Marshal(buf []byte, rem int) ([]byte, int, error) {
+	buf, rem, err := surge.MarshalU32(uint32(b.n), buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("marshaling len: %v", err)
+	}
+	return surge.MarshalBytes(b.bits, buf, rem)
+}
+
+// Unmarshal implements the surge.Unmarshaler interface.
+func (b *BitArray) Unmarshal(buf []byte, rem int) ([]byte, int, error) {
+	var n uint32
+	buf, rem, err := surge.UnmarshalU32(&n, buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("unmarshaling len: %v", err)
+	}
+	b.n = int(n)
+	b.bits = make([]byte, (b.n+7)/8)
+	return surge.UnmarshalBytes(&b.bits, buf, rem)
+}
@@ -0,0 +1,53 @@
+package rng_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/renproject/mpc/rng"
+)
+
+var _ = Describe("BitArray", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	Specify("marshalling and unmarshalling round trips to the same bits, using exactly SizeHint bytes", func() {
+		n := 1 + rand.Intn(100)
+		b := rng.NewBitArray(n)
+		for i := 0; i < n; i++ {
+			if rand.Intn(2) == 0 {
+				b.Set(i)
+			}
+		}
+
+		buf := make([]byte, b.SizeHint())
+		tail, rem, err := b.Marshal(buf, b.SizeHint())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(tail)).To(Equal(0))
+		Expect(rem).To(Equal(0))
+
+		var unmarshalled rng.BitArray
+		tail, rem, err = unmarshalled.Unmarshal(buf, b.SizeHint())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(tail)).To(Equal(0))
+		Expect(rem).To(Equal(0))
+
+		Expect(unmarshalled.Len()).To(Equal(b.Len()))
+		for i := 0; i < n; i++ {
+			Expect(unmarshalled.Get(i)).To(Equal(b.Get(i)))
+		}
+	})
+
+	Specify("marshalling fails when the buffer is smaller than SizeHint", func() {
+		n := 1 + rand.Intn(100)
+		b := rng.NewBitArray(n)
+
+		for max := 0; max < b.SizeHint(); max++ {
+			buf := make([]byte, max)
+			_, _, err := b.Marshal(buf, max)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,50 @@
+package rng
+
+// TransitionEvent describes the event that occurred when calling one of the
+// RNGer state transition functions (New, TransitionShares, TransitionOpen,
+// TransitionOpenBatch, Abort, Reset). See the RNGer documentation for the
+// full set of state transitions that can produce each event.
+type TransitionEvent uint8
+
+const (
+	// Initialised is returned when a new RNGer is constructed.
+	Initialised = TransitionEvent(iota)
+
+	// CommitmentsConstructed is returned when the BRNG shares supplied to
+	// transitionShares were invalid, but the commitments were still used to
+	// construct the Opener for the batch.
+	CommitmentsConstructed
+
+	// SharesConstructed is returned when valid BRNG shares and commitments
+	// were supplied to transitionShares.
+	SharesConstructed
+
+	// SharesIgnored is returned when BRNG shares were supplied while the
+	// RNGer was not in a state that accepts them.
+	SharesIgnored
+
+	// OpeningsIgnored is returned when a directed opening was invalid, or was
+	// supplied while the RNGer was not able to make use of it.
+	OpeningsIgnored
+
+	// OpeningsAdded is returned when a valid directed opening was added to
+	// the underlying Opener, but reconstruction has not yet completed.
+	OpeningsAdded
+
+	// RNGsReconstructed is returned when the kth valid directed opening was
+	// processed and the batch of unbiased random numbers was reconstructed.
+	RNGsReconstructed
+
+	// Reset is returned when the RNGer is reset back to a fresh Init-like
+	// state.
+	Reset
+
+	// RNGAborted is returned when an in-progress RNGer is aborted before it
+	// could reconstruct, returning it to a fresh Init-like state.
+	RNGAborted
+
+	// TimeoutEvent records that a deadline-bound RNGer was found to have
+	// passed its deadline (see RNGer.Expired). No RNGer transition returns
+	// it; the reactor sets it as its last event.
+	TimeoutEvent
+)
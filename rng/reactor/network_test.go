@@ -0,0 +1,270 @@
+package reactor_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/renproject/secp256k1"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/shamirutil"
+
+	"github.com/renproject/mpc/rng"
+	"github.com/renproject/mpc/rng/reactor"
+)
+
+// inMemoryBus is a deterministic, synchronous transport that lets a test
+// inject Byzantine behaviours (dropping, corrupting, relabelling, or
+// duplicating messages) before they reach their destination.
+type inMemoryBus struct {
+	inboxes map[secp256k1.Fn]chan reactor.Message
+	indices []secp256k1.Fn
+
+	// drop lists the indices whose outgoing openings are silently dropped,
+	// simulating a crashed or censoring player.
+	drop map[secp256k1.Fn]bool
+
+	// corrupt lists the indices whose outgoing openings are perturbed so
+	// that they no longer match their commitments.
+	corrupt map[secp256k1.Fn]bool
+
+	// wrongIndex lists the indices whose outgoing openings are relabelled
+	// with another player's index, simulating a player that opens under the
+	// wrong identity.
+	wrongIndex map[secp256k1.Fn]bool
+
+	// duplicate lists the indices whose outgoing openings are delivered
+	// twice, simulating a player that retransmits the same message.
+	duplicate map[secp256k1.Fn]bool
+}
+
+func newInMemoryBus(indices []secp256k1.Fn) *inMemoryBus {
+	bus := &inMemoryBus{
+		inboxes:    make(map[secp256k1.Fn]chan reactor.Message),
+		indices:    indices,
+		drop:       make(map[secp256k1.Fn]bool),
+		corrupt:    make(map[secp256k1.Fn]bool),
+		wrongIndex: make(map[secp256k1.Fn]bool),
+		duplicate:  make(map[secp256k1.Fn]bool),
+	}
+	for _, i := range indices {
+		bus.inboxes[i] = make(chan reactor.Message, len(indices)*4)
+	}
+	return bus
+}
+
+func (bus *inMemoryBus) send(from, to secp256k1.Fn, openings shamir.VerifiableShares) {
+	if bus.drop[from] {
+		return
+	}
+
+	if bus.corrupt[from] {
+		openings = append(shamir.VerifiableShares{}, openings...)
+		shamirutil.PerturbValue(&openings[rand.Intn(len(openings))])
+	}
+
+	if bus.wrongIndex[from] {
+		openings = append(shamir.VerifiableShares{}, openings...)
+		wrong := bus.indices[rand.Intn(len(bus.indices))]
+		for i := range openings {
+			openings[i].Share.Index = wrong
+		}
+	}
+
+	bus.inboxes[to] <- reactor.Message{Type: reactor.DirectedOpening, From: from, Openings: openings}
+}
+
+// deliver sends openings from from to to, duplicating the delivery if from
+// is listed as a duplicating player.
+func (bus *inMemoryBus) deliver(from, to secp256k1.Fn, openings shamir.VerifiableShares) {
+	bus.send(from, to, openings)
+	if bus.duplicate[from] {
+		bus.send(from, to, openings)
+	}
+}
+
+// busTransport adapts an inMemoryBus into a reactor.Transport for a single
+// player, so that a Reactor's own Send calls (rather than test code) are
+// what actually drives delivery, Byzantine corruption included.
+type busTransport struct {
+	bus  *inMemoryBus
+	from secp256k1.Fn
+}
+
+func (t busTransport) Send(peer secp256k1.Fn, opening shamir.VerifiableShares) {
+	t.bus.deliver(t.from, peer, opening)
+}
+
+// sharedBRNGOutputBatch deals b batches of k independent degree-(k-1)
+// Pedersen-VSS secrets to every player in indices, using a single shared
+// dealer table, so that every player's BRNG input is consistent with every
+// other player's - rather than each player reconstructing an unrelated,
+// independently-random invocation. This is what lets the network test check
+// honest reactors for mutual consistency and validate their output against a
+// single, shared commitment.
+func sharedBRNGOutputBatch(indices []secp256k1.Fn, b, k int, h secp256k1.Point) (
+	map[secp256k1.Fn][]shamir.VerifiableShares,
+	[][]shamir.Commitment,
+) {
+	n := len(indices)
+
+	shares := make(map[secp256k1.Fn][]shamir.VerifiableShares, n)
+	for _, index := range indices {
+		shares[index] = make([]shamir.VerifiableShares, b)
+	}
+	coms := make([][]shamir.Commitment, b)
+
+	for i := 0; i < b; i++ {
+		coms[i] = make([]shamir.Commitment, k)
+		dealt := make([]shamir.VerifiableShares, k)
+
+		for d := 0; d < k; d++ {
+			dealt[d] = make(shamir.VerifiableShares, n)
+			coms[i][d] = shamir.NewCommitmentWithCapacity(k)
+			if err := shamir.VShareSecret(&dealt[d], &coms[i][d], indices, h, secp256k1.RandomFn(), k); err != nil {
+				panic(err)
+			}
+		}
+
+		for j, index := range indices {
+			row := make(shamir.VerifiableShares, k)
+			for d := 0; d < k; d++ {
+				row[d] = dealt[d][j]
+			}
+			shares[index][i] = row
+		}
+	}
+
+	return shares, coms
+}
+
+var _ = Describe("Network Simulation", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	It("lets n-k+1 honest reactors reach Done despite Byzantine openings", func() {
+		n := 7 + rand.Intn(3)
+		// n >= 2k-1, so the n-k+1 honest players alone hold k valid openings.
+		k := 4 + rand.Intn((n+1)/2-3)
+		b := 2 + rand.Intn(2)
+
+		indices := shamirutil.RandomIndices(n)
+		h := secp256k1.RandomPoint()
+
+		// A minority of players behave maliciously: they drop, corrupt,
+		// relabel, or duplicate the openings they send out.
+		t := k - 1
+		byzantine := make(map[secp256k1.Fn]bool, t)
+		for len(byzantine) < t {
+			byzantine[indices[rand.Intn(n)]] = true
+		}
+
+		bus := newInMemoryBus(indices)
+		for idx := range byzantine {
+			switch rand.Intn(4) {
+			case 0:
+				bus.drop[idx] = true
+			case 1:
+				bus.corrupt[idx] = true
+			case 2:
+				bus.wrongIndex[idx] = true
+			default:
+				bus.duplicate[idx] = true
+			}
+		}
+
+		sharedShares, sharedComs := sharedBRNGOutputBatch(indices, b, k, h)
+
+		reactors := make(map[secp256k1.Fn]*reactor.Reactor, n)
+
+		// Round 1: every player (honest or Byzantine) seeds its reactor with
+		// its own row of the shared BRNG table. This constructs the player's
+		// RNGer and, via the reactor's own Transport, broadcasts its
+		// directed openings to every peer over the bus - Byzantine
+		// corruption/dropping/relabelling/duplication happens inside the
+		// bus's Send path, not in the test itself.
+		for _, index := range indices {
+			r := reactor.New(index, indices, uint32(b), uint32(k), rng.NewPedersen(h), false, busTransport{bus, index})
+			reactors[index] = r
+
+			seed := make(chan reactor.Message, 1)
+			seed <- reactor.Message{Type: reactor.BrngShares, SetsOfShares: sharedShares[index], SetsOfCommitments: sharedComs}
+			close(seed)
+
+			r.Run(seed, make(chan reactor.Message, 1))
+		}
+
+		// Round 2: every honest reactor drains the directed openings that
+		// Round 1 delivered to its inbox and attempts to reconstruct.
+		honestDone := 0
+		honestShares := make(map[secp256k1.Fn]shamir.VerifiableShares, n)
+		var referenceCommitments []shamir.Commitment
+
+		for index, r := range reactors {
+			if byzantine[index] {
+				continue
+			}
+
+			inbox := bus.inboxes[index]
+			close(inbox)
+
+			out := make(chan reactor.Message, 1)
+			r.Run(inbox, out)
+
+			if r.Status().Event == rng.RNGsReconstructed {
+				honestDone++
+
+				// Every honest reactor accumulates its output commitments
+				// purely from the (shared, public) BRNG commitments, so they
+				// must agree across the whole network regardless of which
+				// reactor computed them.
+				if referenceCommitments == nil {
+					referenceCommitments = r.Commitments()
+				} else {
+					Expect(len(r.Commitments())).To(Equal(len(referenceCommitments)))
+					for i, com := range r.Commitments() {
+						Expect(com.Eq(referenceCommitments[i])).To(BeTrue())
+					}
+				}
+
+				select {
+				case msg := <-out:
+					honestShares[index] = msg.Openings
+
+					// The reconstructed share must validate against the
+					// shared output commitment for every batch item.
+					for i, share := range msg.Openings {
+						Expect(rng.NewPedersen(h).IsValid(&r.Commitments()[i], &share)).To(BeTrue())
+					}
+				default:
+				}
+			}
+		}
+
+		Expect(honestDone).To(BeNumerically(">=", n-k+1))
+
+		// Every honest reactor's reconstructed share is its own point on the
+		// same degree-(k-1) polynomial (one per batch item); if two disjoint
+		// k-subsets of honest reactors interpolate to the same secret, then
+		// every honest reactor is mutually consistent with every other.
+		Expect(len(honestShares)).To(BeNumerically(">=", n-k+1))
+		if len(honestShares) >= 2*k {
+			honestIndices := make([]secp256k1.Fn, 0, len(honestShares))
+			for index := range honestShares {
+				honestIndices = append(honestIndices, index)
+			}
+
+			for i := 0; i < b; i++ {
+				subsetA := make(shamir.Shares, k)
+				subsetB := make(shamir.Shares, k)
+				for j := 0; j < k; j++ {
+					subsetA[j] = honestShares[honestIndices[j]][i].Share
+					subsetB[j] = honestShares[honestIndices[len(honestIndices)-1-j]][i].Share
+				}
+
+				Expect(shamir.Open(subsetA).Eq(shamir.Open(subsetB))).To(BeTrue())
+			}
+		}
+	})
+})
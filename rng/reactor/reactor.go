@@ -0,0 +1,187 @@
+// Package reactor wraps rng.RNGer behind a message-passing driver, so that
+// the pure RNG state machine can be run end-to-end over a network without
+// every caller having to hand-roll the loop of feeding BRNG shares and
+// directed openings into it. The design mirrors Tendermint's reactor
+// pattern: a reactor consumes an inbound channel of Messages and produces an
+// outbound channel of Messages, and is otherwise agnostic to the transport
+// that carries them.
+package reactor
+
+import (
+	"time"
+
+	"github.com/renproject/secp256k1"
+	"github.com/renproject/shamir"
+
+	"github.com/renproject/mpc/rng"
+)
+
+// MessageType identifies the kind of payload carried by a Message.
+type MessageType uint8
+
+const (
+	// BrngShares carries the BRNG shares and commitments that seed a new RNG
+	// invocation.
+	BrngShares = MessageType(iota)
+
+	// DirectedOpening carries another player's directed opening of its
+	// share.
+	DirectedOpening
+
+	// Timeout is a tick raised by the driver to check the RNGer's deadline.
+	// The reactor builds its RNGer with rng.New, which sets no deadline, so
+	// a Timeout currently has no effect.
+	Timeout
+
+	// AbortCmd instructs the reactor to abandon the current invocation.
+	AbortCmd
+
+	// Reconstructed carries this reactor's final reconstructed shares, once
+	// enough directed openings have been received. It is only ever produced
+	// by a Reactor on its out channel, never consumed on its in channel.
+	Reconstructed
+)
+
+// Message is a single unit of communication in and out of a Reactor.
+type Message struct {
+	Type MessageType
+
+	// From is the sender's index. It is unset for locally-raised messages
+	// such as Timeout and AbortCmd.
+	From secp256k1.Fn
+
+	SetsOfShares      []shamir.VerifiableShares
+	SetsOfCommitments [][]shamir.Commitment
+	Openings          shamir.VerifiableShares
+}
+
+// Transport is the interface a Reactor uses to send directed openings to its
+// peers. It can be backed by libp2p, an in-memory test bus, or any other
+// network.
+type Transport interface {
+	// Send delivers a directed opening to the given peer.
+	Send(peer secp256k1.Fn, opening shamir.VerifiableShares)
+}
+
+// Status is a point-in-time snapshot of a Reactor, suitable for surfacing to
+// an operator debugging a stalled run.
+type Status struct {
+	Event         rng.TransitionEvent
+	OpeningsCount int
+	MissingPeers  *rng.BitArray
+}
+
+// Reactor drives an rng.RNGer using a message bus rather than requiring the
+// caller to invoke its transitions directly. It is constructed with the
+// parameters rng.New itself needs, but does not build the underlying RNGer
+// until a BrngShares message seeds it via Run.
+type Reactor struct {
+	ownIndex secp256k1.Fn
+	indices  []secp256k1.Fn
+	b, k     uint32
+	scheme   rng.CommitmentScheme
+	isZero   bool
+
+	rnger     rng.RNGer
+	started   bool
+	transport Transport
+
+	lastEvent   rng.TransitionEvent
+	commitments []shamir.Commitment
+}
+
+// New constructs a Reactor for the given player. Its RNGer is not built
+// until Run receives a BrngShares message.
+func New(ownIndex secp256k1.Fn, indices []secp256k1.Fn, b, k uint32, scheme rng.CommitmentScheme, isZero bool, transport Transport) *Reactor {
+	return &Reactor{
+		ownIndex:  ownIndex,
+		indices:   indices,
+		b:         b,
+		k:         k,
+		scheme:    scheme,
+		isZero:    isZero,
+		transport: transport,
+		lastEvent: rng.Initialised,
+	}
+}
+
+// Status returns a snapshot of the reactor's progress.
+func (r *Reactor) Status() Status {
+	if !r.started {
+		return Status{Event: r.lastEvent}
+	}
+	return Status{
+		Event:         r.lastEvent,
+		OpeningsCount: r.rnger.ReceivedOpenings().Count(),
+		MissingPeers:  r.rnger.MissingOpenings(),
+	}
+}
+
+// Commitments returns the batch of output commitments this reactor's RNGer
+// accumulated from its BRNG shares, one per batch item. A caller can check a
+// Reconstructed message's shares against these to verify that reconstruction
+// produced a value consistent with what every other player in the network
+// should also reconstruct. It is nil until a BrngShares message has seeded
+// the reactor.
+func (r *Reactor) Commitments() []shamir.Commitment {
+	return r.commitments
+}
+
+// Run drains in until it is closed, the RNGer reaches Done, an AbortCmd
+// arrives, or a Timeout finds the RNGer expired, writing
+// progress and reconstruction results to out. The first message it expects
+// is a BrngShares, which builds the underlying RNGer and broadcasts this
+// player's own directed openings to every peer over the transport; every
+// DirectedOpening received afterwards is fed into that RNGer.
+func (r *Reactor) Run(in <-chan Message, out chan<- Message) {
+	for msg := range in {
+		switch msg.Type {
+		case BrngShares:
+			event, rnger, openingsMap, commitments := rng.New(r.ownIndex, r.indices, r.b, r.k, r.scheme, msg.SetsOfShares, msg.SetsOfCommitments, r.isZero)
+			r.rnger = rnger
+			r.started = true
+			r.lastEvent = event
+			r.commitments = commitments
+
+			for _, peer := range r.indices {
+				if peer.Eq(&r.ownIndex) {
+					continue
+				}
+				r.Send(peer, openingsMap[peer])
+			}
+
+			// A threshold of k = 1 reconstructs immediately on construction,
+			// but rng.New does not hand back the reconstructed shares in
+			// that case, so there is nothing further to do here.
+
+		case DirectedOpening:
+			event, shares := r.rnger.TransitionOpen(msg.From, msg.Openings)
+			r.lastEvent = event
+
+			if event == rng.RNGsReconstructed {
+				out <- Message{Type: Reconstructed, Openings: shares}
+				return
+			}
+
+		case AbortCmd:
+			event, _ := r.rnger.Abort()
+			r.lastEvent = event
+			return
+
+		case Timeout:
+			if r.rnger.Expired(time.Now()) {
+				r.lastEvent = rng.TimeoutEvent
+				return
+			}
+		}
+	}
+}
+
+// Send broadcasts a directed opening for the given peer over the reactor's
+// transport, if one was supplied to New.
+func (r *Reactor) Send(peer secp256k1.Fn, opening shamir.VerifiableShares) {
+	if r.transport == nil {
+		return
+	}
+	r.transport.Send(peer, opening)
+}
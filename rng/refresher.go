@@ -0,0 +1,169 @@
+package rng
+
+import (
+	"github.com/renproject/secp256k1"
+	"github.com/renproject/shamir"
+
+	"github.com/renproject/mpc/open"
+)
+
+// Refresher is a state machine that rotates an existing batch of
+// shamir.VerifiableShares under fresh randomness, without changing the
+// secrets they represent. It wraps an RNGer configured to produce Random
+// Zero shares (RZG, via the isZero path in transitionShares) and additively
+// combines the resulting shares and commitments with the old batch, so the
+// reconstructed secret is unchanged but every participant's share (and
+// decommitment) is randomized.
+//
+// Refresher's transitions mirror RNGer: Init -> WaitingOpen -> Done.
+type Refresher struct {
+	rzg RNGer
+
+	oldShares      shamir.VerifiableShares
+	oldCommitments []shamir.Commitment
+}
+
+// NewRefresher constructs a Refresher for an existing batch of shares and
+// commitments, given b sets of BRNG-derived zero shares (one set per
+// threshold-1 contributors) and their commitments.
+//
+// - Inputs
+//   - ownIndex, indices, k, scheme are as for rng.New
+//   - oldShares, oldCommitments are the previously issued batch to be
+//     refreshed
+//   - zeroSetsOfShares, zeroSetsOfCommitments are the BRNG outputs for the
+//     zero-sharing (RZG) that will randomize the batch
+func NewRefresher(
+	ownIndex secp256k1.Fn,
+	indices []secp256k1.Fn,
+	k uint32,
+	scheme CommitmentScheme,
+	oldShares shamir.VerifiableShares,
+	oldCommitments []shamir.Commitment,
+	zeroSetsOfShares []shamir.VerifiableShares,
+	zeroSetsOfCommitments [][]shamir.Commitment,
+) (TransitionEvent, Refresher, map[secp256k1.Fn]shamir.VerifiableShares, []shamir.Commitment) {
+	b := uint32(len(oldShares))
+
+	event, rzg, openingsMap, zeroCommitments := New(
+		ownIndex, indices, b, k, scheme,
+		zeroSetsOfShares, zeroSetsOfCommitments, true,
+	)
+
+	refresher := Refresher{
+		rzg:            rzg,
+		oldShares:      oldShares,
+		oldCommitments: oldCommitments,
+	}
+
+	return event, refresher, openingsMap, combineCommitments(oldCommitments, zeroCommitments)
+}
+
+// TransitionOpen forwards a directed opening of a zero share to the
+// underlying RZG. When the RZG reconstructs, the zero shares are added to
+// the old batch and the refreshed shares are returned.
+func (refresher *Refresher) TransitionOpen(from secp256k1.Fn, openings shamir.VerifiableShares) (TransitionEvent, shamir.VerifiableShares) {
+	event, zeroShares := refresher.rzg.TransitionOpen(from, openings)
+	if event != RNGsReconstructed {
+		return event, nil
+	}
+
+	return event, combineShares(refresher.oldShares, zeroShares)
+}
+
+// ReceivedOpenings exposes the underlying RZG's bitmap of received openings.
+func (refresher Refresher) ReceivedOpenings() *BitArray {
+	return refresher.rzg.ReceivedOpenings()
+}
+
+// MissingOpenings exposes the underlying RZG's bitmap of outstanding
+// openings.
+func (refresher Refresher) MissingOpenings() *BitArray {
+	return refresher.rzg.MissingOpenings()
+}
+
+func combineShares(old, zero shamir.VerifiableShares) shamir.VerifiableShares {
+	combined := make(shamir.VerifiableShares, len(old))
+	for i := range old {
+		combined[i] = old[i]
+		combined[i].Add(&combined[i], &zero[i])
+	}
+	return combined
+}
+
+func combineCommitments(old, zero []shamir.Commitment) []shamir.Commitment {
+	if zero == nil {
+		return old
+	}
+
+	combined := make([]shamir.Commitment, len(old))
+	for i := range old {
+		combined[i].Set(old[i])
+		combined[i].Add(&combined[i], &zero[i])
+	}
+	return combined
+}
+
+// HandoffRefresher is a "committee handoff" variant of Refresher: rather
+// than producing a new share for the same committee, the old committee
+// generates directed openings of a fresh bivariate sharing for a (possibly
+// entirely different) new committee with its own threshold k'. This mirrors
+// the CHURP-style handoff protocol, and lets a long-lived secret survive
+// across epochs instead of only ever being usable for a single-shot random
+// beacon.
+type HandoffRefresher struct {
+	oldIndex   secp256k1.Fn
+	oldIndices []secp256k1.Fn
+
+	// newIndex is this new-committee member's own index, used to tag the
+	// share reconstructed once enough old-committee openings have arrived for
+	// the Opener to reconstruct.
+	newIndex    secp256k1.Fn
+	newIndices  []secp256k1.Fn
+	newK        uint32
+	newOpener   open.Opener
+	commitments []shamir.Commitment
+}
+
+// NewHandoffRefresher constructs a HandoffRefresher for a new-committee
+// member. oldIndex and oldIndices identify the old committee whose openings
+// will be received; newIndex is this member's own index within newIndices;
+// h is the Pedersen parameter shared by both committees.
+func NewHandoffRefresher(
+	oldIndex secp256k1.Fn,
+	oldIndices []secp256k1.Fn,
+	newIndex secp256k1.Fn,
+	newIndices []secp256k1.Fn,
+	newK uint32,
+	h secp256k1.Point,
+	summedCommitments []shamir.Commitment,
+) HandoffRefresher {
+	return HandoffRefresher{
+		oldIndex:    oldIndex,
+		oldIndices:  oldIndices,
+		newIndex:    newIndex,
+		newIndices:  newIndices,
+		newK:        newK,
+		newOpener:   open.New(summedCommitments, newIndices, h),
+		commitments: summedCommitments,
+	}
+}
+
+// TransitionOpen feeds a directed opening, produced by an old-committee
+// member's bivariate resharing, into the new committee's Opener.
+func (h *HandoffRefresher) TransitionOpen(openings shamir.VerifiableShares) (TransitionEvent, shamir.VerifiableShares) {
+	event, secrets, decommitments := h.newOpener.HandleShareBatch(openings)
+	if event != open.Done {
+		if event == open.SharesAdded {
+			return OpeningsAdded, nil
+		}
+		return OpeningsIgnored, nil
+	}
+
+	shares := make(shamir.VerifiableShares, len(secrets))
+	for i, secret := range secrets {
+		share := shamir.NewShare(h.newIndex, secret)
+		shares[i] = shamir.NewVerifiableShare(share, decommitments[i])
+	}
+	return RNGsReconstructed, shares
+}
@@ -0,0 +1,242 @@
+package rng_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/renproject/secp256k1"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/shamirutil"
+
+	"github.com/renproject/mpc/rng"
+	"github.com/renproject/mpc/rng/rngutil"
+)
+
+var _ = Describe("Refresher", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	Specify("refreshing a batch produces a new share set of the same shape", func() {
+		n := 5 + rand.Intn(6)
+		indices := shamirutil.RandomIndices(n)
+		index := indices[rand.Intn(len(indices))]
+		otherIndices := make([]secp256k1.Fn, 0, n-1)
+		for _, i := range indices {
+			if !i.Eq(&index) {
+				otherIndices = append(otherIndices, i)
+			}
+		}
+
+		b := 3 + rand.Intn(3)
+		k := 3 + rand.Intn(n-3)
+		h := secp256k1.RandomPoint()
+
+		// The old batch this refresh is rotating; only its shape (length b)
+		// matters for this test, since the combination logic is purely
+		// additive.
+		oldShares := make(shamir.VerifiableShares, b)
+		oldCommitments := make([]shamir.Commitment, b)
+		for i := range oldCommitments {
+			oldCommitments[i] = shamir.NewCommitmentWithCapacity(int(k))
+		}
+
+		zeroSetsOfShares, zeroSetsOfCommitments, zeroOpeningsByPlayer, _ :=
+			rngutil.RNGSharesBatch(indices, index, b, k, h, true)
+
+		event, refresher, _, newCommitments := rng.NewRefresher(
+			index, indices, uint32(k), rng.NewPedersen(h),
+			oldShares, oldCommitments,
+			zeroSetsOfShares, zeroSetsOfCommitments,
+		)
+
+		Expect(event).To(Equal(rng.SharesConstructed))
+		Expect(len(newCommitments)).To(Equal(b))
+
+		for _, from := range otherIndices[:k-1] {
+			event, shares := refresher.TransitionOpen(from, zeroOpeningsByPlayer[from])
+			if event == rng.RNGsReconstructed {
+				Expect(len(shares)).To(Equal(b))
+				break
+			}
+			Expect(event).To(Equal(rng.OpeningsAdded))
+		}
+	})
+
+	Specify("refreshing a batch does not change the secret it reconstructs to", func() {
+		n := 5 + rand.Intn(6)
+		k := 3 + rand.Intn(n-3)
+		b := 2
+		indices := shamirutil.RandomIndices(n)
+		h := secp256k1.RandomPoint()
+
+		// Build a genuine Shamir sharing of a random secret per batch
+		// element, rather than the zero-shaped placeholder used above, so
+		// that refreshing it can be checked to preserve the reconstructed
+		// value.
+		secrets := make([]secp256k1.Fn, b)
+		oldSharesByPlayer := make(map[secp256k1.Fn]shamir.VerifiableShares, n)
+		for _, index := range indices {
+			oldSharesByPlayer[index] = make(shamir.VerifiableShares, b)
+		}
+		for j := 0; j < b; j++ {
+			secrets[j] = secp256k1.RandomFn()
+			coeffs := make([]secp256k1.Fn, k)
+			coeffs[0] = secrets[j]
+			for c := 1; c < k; c++ {
+				coeffs[c] = secp256k1.RandomFn()
+			}
+			for _, index := range indices {
+				value := evalPoly(coeffs, index)
+				oldSharesByPlayer[index][j] = shamir.NewVerifiableShare(
+					shamir.NewShare(index, value), secp256k1.NewFnFromU32(0),
+				)
+			}
+		}
+
+		oldCommitments := make([]shamir.Commitment, b)
+		for i := range oldCommitments {
+			oldCommitments[i] = shamir.NewCommitmentWithCapacity(k)
+		}
+
+		// Run every player's Refresher through a full round so that each one
+		// reaches its own refreshed batch. Every player takes its zero shares
+		// from one shared dealer table, so that all of them refresh with the
+		// same zero sharing, and each player's directed openings are routed to
+		// their recipients.
+		zeroSharesByPlayer, zeroSetsOfCommitments := sharedZeroBRNGOutputBatch(indices, b, k, h)
+		refreshers := make(map[secp256k1.Fn]*rng.Refresher, n)
+		openingsByRecipient := make(map[secp256k1.Fn]map[secp256k1.Fn]shamir.VerifiableShares, n)
+		for _, index := range indices {
+			openingsByRecipient[index] = make(map[secp256k1.Fn]shamir.VerifiableShares, n)
+		}
+
+		for _, index := range indices {
+			_, refresher, openingsMap, _ := rng.NewRefresher(
+				index, indices, uint32(k), rng.NewPedersen(h),
+				oldSharesByPlayer[index], oldCommitments,
+				zeroSharesByPlayer[index], zeroSetsOfCommitments,
+			)
+			refreshers[index] = &refresher
+			for to, openings := range openingsMap {
+				openingsByRecipient[to][index] = openings
+			}
+		}
+
+		finalSharesByPlayer := make(map[secp256k1.Fn]shamir.VerifiableShares, n)
+		for _, index := range indices {
+			refresher := refreshers[index]
+			for _, from := range indices {
+				if from.Eq(&index) {
+					continue
+				}
+				event, shares := refresher.TransitionOpen(from, openingsByRecipient[index][from])
+				if event == rng.RNGsReconstructed {
+					finalSharesByPlayer[index] = shares
+					break
+				}
+			}
+		}
+		Expect(len(finalSharesByPlayer)).To(BeNumerically(">=", k))
+
+		sampleIndices := make([]secp256k1.Fn, 0, k)
+		for index := range finalSharesByPlayer {
+			if len(sampleIndices) == k {
+				break
+			}
+			sampleIndices = append(sampleIndices, index)
+		}
+
+		for j := 0; j < b; j++ {
+			var reconstructed secp256k1.Fn
+			for _, index := range sampleIndices {
+				lambda := lagrangeCoefficient(index, sampleIndices)
+				value := finalSharesByPlayer[index][j].Share.Value
+
+				var term secp256k1.Fn
+				term.Mul(&lambda, &value)
+				reconstructed.Add(&reconstructed, &term)
+			}
+
+			Expect(reconstructed.Eq(&secrets[j])).To(BeTrue())
+		}
+	})
+})
+
+// sharedZeroBRNGOutputBatch deals, for each of b batch items, k-1
+// independent degree-(k-1) Pedersen-VSS secrets to every player in indices
+// from a single shared dealer table, so that every player's RZG input belongs
+// to the same zero sharing.
+func sharedZeroBRNGOutputBatch(indices []secp256k1.Fn, b, k int, h secp256k1.Point) (
+	map[secp256k1.Fn][]shamir.VerifiableShares,
+	[][]shamir.Commitment,
+) {
+	n := len(indices)
+
+	shares := make(map[secp256k1.Fn][]shamir.VerifiableShares, n)
+	for _, index := range indices {
+		shares[index] = make([]shamir.VerifiableShares, b)
+	}
+	coms := make([][]shamir.Commitment, b)
+
+	for i := 0; i < b; i++ {
+		coms[i] = make([]shamir.Commitment, k-1)
+		dealt := make([]shamir.VerifiableShares, k-1)
+
+		for d := 0; d < k-1; d++ {
+			dealt[d] = make(shamir.VerifiableShares, n)
+			coms[i][d] = shamir.NewCommitmentWithCapacity(k)
+			if err := shamir.VShareSecret(&dealt[d], &coms[i][d], indices, h, secp256k1.RandomFn(), k); err != nil {
+				panic(err)
+			}
+		}
+
+		for j, index := range indices {
+			row := make(shamir.VerifiableShares, k-1)
+			for d := 0; d < k-1; d++ {
+				row[d] = dealt[d][j]
+			}
+			shares[index][i] = row
+		}
+	}
+
+	return shares, coms
+}
+
+// evalPoly evaluates the polynomial with the given coefficients (lowest
+// degree first) at x, using Horner's method.
+func evalPoly(coeffs []secp256k1.Fn, x secp256k1.Fn) secp256k1.Fn {
+	result := coeffs[len(coeffs)-1]
+	for i := len(coeffs) - 2; i >= 0; i-- {
+		result.Mul(&result, &x)
+		result.Add(&result, &coeffs[i])
+	}
+	return result
+}
+
+// lagrangeCoefficient computes lambda_i, the Lagrange coefficient for index
+// i within the given set of indices, evaluated at x=0.
+func lagrangeCoefficient(index secp256k1.Fn, indices []secp256k1.Fn) secp256k1.Fn {
+	num := secp256k1.NewFnFromU32(1)
+	den := secp256k1.NewFnFromU32(1)
+
+	for _, j := range indices {
+		if j.Eq(&index) {
+			continue
+		}
+
+		num.Mul(&num, &j)
+
+		var diff secp256k1.Fn
+		diff.Sub(&j, &index)
+		den.Mul(&den, &diff)
+	}
+
+	var inv secp256k1.Fn
+	inv.Inverse(&den)
+
+	var lambda secp256k1.Fn
+	lambda.Mul(&num, &inv)
+	return lambda
+}
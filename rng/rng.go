@@ -2,20 +2,20 @@ package rng
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/renproject/secp256k1"
 	"github.com/renproject/shamir"
 	"github.com/renproject/surge"
 
 	"github.com/renproject/mpc/open"
-	"github.com/renproject/mpc/rng/compute"
 )
 
 // RNGer describes the structure of the Random Number Generation machine. The
 // machine can be used for an arbitrary number of invocations of RNG, however
 // each instance is specific to the set of machine indices it was constructed
-// with, as well as the batch size, reconstruction threshold and Pedersen
-// Commitment Scheme Parameter.
+// with, as well as the batch size, reconstruction threshold and
+// CommitmentScheme.
 //
 // RNGer can exist in one of the following states:
 // - Init
@@ -23,11 +23,11 @@ import (
 // - Done
 //
 // A new instance of RNGer can be created by calling:
-// - New(index, indices, b, k, h)
+// - New(index, indices, b, k, scheme)
 //
 // State transitions can be triggered by three different functions:
 // - TransitionShares(setsOfShares, setsOfCommitments)
-// - TransitionOpen(openings)
+// - TransitionOpen(from, openings)
 // - Reset
 //
 // Every state transition function returns a transition event, depending on how
@@ -78,6 +78,31 @@ type RNGer struct {
 	// opener state machine also transitions, to eventually reconstruct the
 	// batchSize number of secrets.
 	opener open.Opener
+
+	// openingsReceived tracks, per position in indices, whether a valid
+	// directed opening has been received from that player. It is exposed via
+	// ReceivedOpenings/MissingOpenings so that a networking layer can gossip
+	// compact "have/want" summaries and drive selective re-requests.
+	openingsReceived BitArray
+
+	// consumedShares holds the input BRNG shares that were consumed when this
+	// RNGer was constructed, so that Abort can return them to the caller for
+	// reuse in a new instance.
+	consumedShares []shamir.VerifiableShares
+
+	// deadline is the time by which this RNGer is expected to have
+	// reconstructed, if one was set via NewWithDeadline. The zero value means
+	// no deadline was set.
+	deadline time.Time
+
+	// scheme is the CommitmentScheme this RNGer was constructed with. It
+	// supplies the Opener's parameter (against which directed openings are
+	// checked; the BRNG shares themselves are not validated), how locally-held shares and
+	// commitments are accumulated, and how the final reconstructed share is
+	// built (in particular, whether it carries a decommitment). It is also
+	// retained so that Abort can rebuild a fresh Opener without requiring the
+	// caller to supply a Pedersen parameter again.
+	scheme CommitmentScheme
 }
 
 // N returns the number of machine replicas participating in the RNG protocol.
@@ -105,7 +130,9 @@ func (rnger RNGer) Threshold() uint32 {
 // 	 - indices is the set of player indices
 // 	 - b is the number of random numbers generated in one invocation of the protocol
 // 	 - k is the reconstruction threshold for every random number
-// 	 - h is the Pedersen Commitment Parameter, a point on elliptic curve
+// 	 - scheme is the CommitmentScheme (Pedersen or Feldman) whose parameter
+// 	   the Opener checks directed openings against, and which builds the
+// 	   final reconstructed share; the BRNG shares are not validated
 //
 // - Returns
 //	 - TransitionEvent is the `Initialised` event emitted on creation
@@ -114,7 +141,7 @@ func New(
 	ownIndex secp256k1.Fn,
 	indices []secp256k1.Fn,
 	b, k uint32,
-	h secp256k1.Point,
+	scheme CommitmentScheme,
 	setsOfShares []shamir.VerifiableShares,
 	setsOfCommitments [][]shamir.Commitment,
 	isZero bool,
@@ -135,21 +162,119 @@ func New(
 	// FIXME: Move transitionShares logic into here to avoid having to have
 	// this temporary opener.
 	commitmentBatch := []shamir.Commitment{shamir.Commitment{secp256k1.Point{}}}
-	opener := open.New(commitmentBatch, indices, h)
+	opener := open.New(commitmentBatch, indices, scheme.Parameter())
 
 	rnger := RNGer{
-		index:     ownIndex,
-		indices:   indices,
-		batchSize: b,
-		threshold: k,
-		opener:    opener,
+		index:            ownIndex,
+		indices:          indices,
+		batchSize:        b,
+		threshold:        k,
+		opener:           opener,
+		openingsReceived: NewBitArray(len(indices)),
+		scheme:           scheme,
 	}
 
-	event, openingsMap, _, commitments := rnger.transitionShares(setsOfShares, setsOfCommitments, isZero, h)
+	event, openingsMap, _, commitments := rnger.transitionShares(setsOfShares, setsOfCommitments, isZero)
+
+	if event == SharesConstructed || event == RNGsReconstructed {
+		if pos, ok := rnger.positionOf(ownIndex); ok {
+			rnger.openingsReceived.Set(pos)
+		}
+		rnger.consumedShares = setsOfShares
+	}
 
 	return event, rnger, openingsMap, commitments
 }
 
+// NewWithDeadline is identical to New, but additionally records an intended
+// deadline for this RNGer invocation. A driver can poll Expired(now) and
+// raise a TimeoutEvent deterministically, rather than relying on its own
+// separate timer bookkeeping.
+func NewWithDeadline(
+	ownIndex secp256k1.Fn,
+	indices []secp256k1.Fn,
+	b, k uint32,
+	scheme CommitmentScheme,
+	setsOfShares []shamir.VerifiableShares,
+	setsOfCommitments [][]shamir.Commitment,
+	isZero bool,
+	deadline time.Time,
+) (TransitionEvent, RNGer, map[secp256k1.Fn]shamir.VerifiableShares, []shamir.Commitment) {
+	event, rnger, openingsMap, commitments := New(ownIndex, indices, b, k, scheme, setsOfShares, setsOfCommitments, isZero)
+	rnger.deadline = deadline
+	return event, rnger, openingsMap, commitments
+}
+
+// Scheme returns the CommitmentScheme this RNGer was constructed with.
+func (rnger RNGer) Scheme() CommitmentScheme {
+	return rnger.scheme
+}
+
+// Expired reports whether now is at or past the deadline set via
+// NewWithDeadline. If no deadline was set, Expired always returns false.
+func (rnger RNGer) Expired(now time.Time) bool {
+	if rnger.deadline.IsZero() {
+		return false
+	}
+	return !now.Before(rnger.deadline)
+}
+
+// UnlockShares returns the input BRNG shares that were consumed by this
+// RNGer when it was constructed. This allows a caller that is aborting a
+// stalled invocation to feed the same shares into a new instance, or to
+// reuse them for a different batch, rather than having them locked forever
+// inside a stuck state machine.
+func (rnger RNGer) UnlockShares() []shamir.VerifiableShares {
+	return rnger.consumedShares
+}
+
+// Abort unconditionally returns the RNGer to a fresh Init-like state,
+// discarding any directed openings that have been received so far. This is
+// the escape hatch for when fewer than k players ever send valid openings
+// and the machine would otherwise be stuck in WaitingOpen forever. The
+// shares that were consumed on construction are returned as Abort's second
+// result so that the caller can reuse them (e.g. to retry with a different
+// deadline, or to fold them into a new batch); after Abort, UnlockShares
+// returns nil.
+func (rnger *RNGer) Abort() (TransitionEvent, []shamir.VerifiableShares) {
+	shares := rnger.consumedShares
+
+	commitmentBatch := []shamir.Commitment{shamir.Commitment{secp256k1.Point{}}}
+	rnger.opener = open.New(commitmentBatch, rnger.indices, rnger.scheme.Parameter())
+	rnger.openingsReceived = NewBitArray(len(rnger.indices))
+	rnger.consumedShares = nil
+	rnger.deadline = time.Time{}
+
+	return RNGAborted, shares
+}
+
+// positionOf returns the position of the given player index within the
+// RNGer's indices slice, as used to address bits in openingsReceived.
+func (rnger RNGer) positionOf(index secp256k1.Fn) (int, bool) {
+	for i, idx := range rnger.indices {
+		if idx.Eq(&index) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// ReceivedOpenings returns a bitmap, indexed by position in the RNGer's
+// indices, indicating which players' directed openings have already been
+// received and accepted. It is cheap to serialise (see BitArray) so that it
+// can be gossiped to drive selective re-requests of missing openings.
+func (rnger RNGer) ReceivedOpenings() *BitArray {
+	received := rnger.openingsReceived
+	return &received
+}
+
+// MissingOpenings returns the complement of ReceivedOpenings: a bitmap of
+// the players whose directed openings are still outstanding.
+func (rnger RNGer) MissingOpenings() *BitArray {
+	missing := rnger.openingsReceived.Not()
+	return &missing
+}
+
 // TransitionShares performs the state transition for the RNG state machine
 // from `Init` to `WaitingOpen`, upon receiving `b` sets of verifiable shares
 // and their respective commitments. The machine should locally compute its
@@ -188,7 +313,6 @@ func (rnger *RNGer) transitionShares(
 	setsOfShares []shamir.VerifiableShares,
 	setsOfCommitments [][]shamir.Commitment,
 	isZero bool,
-	h secp256k1.Point,
 ) (
 	TransitionEvent,
 	map[secp256k1.Fn]shamir.VerifiableShares,
@@ -259,7 +383,7 @@ func (rnger *RNGer) transitionShares(
 
 		// Compute the share commitment and add it to the local set of
 		// commitments.
-		accCommitment := compute.ShareCommitment(rnger.index, setOfCommitments)
+		accCommitment := rnger.scheme.ShareCommitment(rnger.index, setOfCommitments)
 		if isZero {
 			accCommitment.Scale(accCommitment, &rnger.index)
 		}
@@ -273,7 +397,7 @@ func (rnger *RNGer) transitionShares(
 	if !ignoreShares {
 		for _, j := range rnger.indices {
 			for _, setOfShares := range setsOfShares {
-				accShare := compute.ShareOfShare(j, setOfShares)
+				accShare := rnger.scheme.ShareOfShare(j, setOfShares)
 				if isZero {
 					accShare.Scale(&accShare, &j)
 				}
@@ -283,7 +407,7 @@ func (rnger *RNGer) transitionShares(
 	}
 
 	// Reset the Opener machine with the computed commitments.
-	rnger.opener = open.New(locallyComputedCommitments, rnger.indices, h)
+	rnger.opener = open.New(locallyComputedCommitments, rnger.indices, rnger.scheme.Parameter())
 
 	if ignoreShares {
 		return CommitmentsConstructed, openingsMap, nil, commitments
@@ -296,8 +420,7 @@ func (rnger *RNGer) transitionShares(
 	if event == open.Done {
 		shares := make(shamir.VerifiableShares, rnger.batchSize)
 		for i, secret := range secrets {
-			share := shamir.NewShare(rnger.index, secret)
-			shares[i] = shamir.NewVerifiableShare(share, decommitments[i])
+			shares[i] = rnger.scheme.Combine(rnger.index, secret, decommitments[i])
 		}
 		return RNGsReconstructed, openingsMap, shares, commitments
 	}
@@ -323,6 +446,8 @@ func (rnger *RNGer) transitionShares(
 // `r_j` for the `b` random numbers.
 //
 // - Inputs
+//   - from is the index of the player that sent these openings, and is used
+//     to address the corresponding bit in ReceivedOpenings/MissingOpenings
 //   - openings are the directed openings
 //	   - MUST be of length b (batch size)
 //	   - Will be ignored if they're not consistent with their respective commitments
@@ -334,33 +459,92 @@ func (rnger *RNGer) transitionShares(
 // 		- RNGsReconstructed when the set of openings was the kth valid set and
 // 			hence the RNGer could reconstruct its shares for the unbiased
 // 			random numbers
-func (rnger *RNGer) TransitionOpen(openings shamir.VerifiableShares) (TransitionEvent, shamir.VerifiableShares) {
+func (rnger *RNGer) TransitionOpen(from secp256k1.Fn, openings shamir.VerifiableShares) (TransitionEvent, shamir.VerifiableShares) {
 	// Pass these openings to the Opener state machine now that we have already
 	// received valid commitments from BRNG outputs.
 	event, secrets, decommitments := rnger.opener.HandleShareBatch(openings)
 
 	switch event {
 	case open.Done:
+		if pos, ok := rnger.positionOf(from); ok {
+			rnger.openingsReceived.Set(pos)
+		}
 		shares := make(shamir.VerifiableShares, rnger.batchSize)
 		for i, secret := range secrets {
-			share := shamir.NewShare(rnger.index, secret)
-			shares[i] = shamir.NewVerifiableShare(share, decommitments[i])
+			shares[i] = rnger.scheme.Combine(rnger.index, secret, decommitments[i])
 		}
 		return RNGsReconstructed, shares
 	case open.SharesAdded:
+		if pos, ok := rnger.positionOf(from); ok {
+			rnger.openingsReceived.Set(pos)
+		}
 		return OpeningsAdded, nil
 	default:
 		return OpeningsIgnored, nil
 	}
 }
 
+// DirectedOpening pairs a directed opening batch with the index of the
+// player it was received from, so that a batch of mailbox messages can be
+// drained in a single call to TransitionOpenBatch.
+type DirectedOpening struct {
+	From     secp256k1.Fn
+	Openings shamir.VerifiableShares
+}
+
+// OpenBatchSummary summarises the effect of processing a batch of directed
+// openings in one call to TransitionOpenBatch.
+type OpenBatchSummary struct {
+	// Added lists the senders whose openings were accepted.
+	Added []secp256k1.Fn
+
+	// Ignored lists the senders whose openings were invalid and therefore
+	// ignored.
+	Ignored []secp256k1.Fn
+
+	// Event is the event of the last accepted opening in the batch
+	// (OpeningsAdded or RNGsReconstructed), or OpeningsIgnored if no opening
+	// was accepted; it is RNGsReconstructed if reconstruction completed at
+	// any point during the batch.
+	Event TransitionEvent
+}
+
+// TransitionOpenBatch drains a batch of directed openings, received from
+// potentially many different peers, in a single call. This is convenient for
+// a caller that wants to empty an inbound mailbox in one go rather than
+// calling TransitionOpen once per message. Processing stops as soon as the
+// RNGer reconstructs; ignored openings do not stop it. The summary reports
+// every opening that was looked at up to that point.
+func (rnger *RNGer) TransitionOpenBatch(batch []DirectedOpening) (OpenBatchSummary, shamir.VerifiableShares) {
+	summary := OpenBatchSummary{Event: OpeningsIgnored}
+
+	for _, dirOpening := range batch {
+		event, shares := rnger.TransitionOpen(dirOpening.From, dirOpening.Openings)
+
+		switch event {
+		case OpeningsAdded:
+			summary.Added = append(summary.Added, dirOpening.From)
+			summary.Event = OpeningsAdded
+		case RNGsReconstructed:
+			summary.Added = append(summary.Added, dirOpening.From)
+			summary.Event = RNGsReconstructed
+			return summary, shares
+		default:
+			summary.Ignored = append(summary.Ignored, dirOpening.From)
+		}
+	}
+
+	return summary, nil
+}
+
 // SizeHint implements the surge.SizeHinter interface.
 func (rnger RNGer) SizeHint() int {
 	return rnger.index.SizeHint() +
 		surge.SizeHint(rnger.indices) +
 		surge.SizeHint(rnger.batchSize) +
 		surge.SizeHint(rnger.threshold) +
-		rnger.opener.SizeHint()
+		rnger.opener.SizeHint() +
+		rnger.openingsReceived.SizeHint()
 }
 
 // Marshal implements the surge.Marshaler interface.
@@ -385,6 +569,10 @@ func (rnger RNGer) Marshal(buf []byte, rem int) ([]byte, int, error) {
 	if err != nil {
 		return buf, rem, fmt.Errorf("marshaling opener: %v", err)
 	}
+	buf, rem, err = rnger.openingsReceived.Marshal(buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("marshaling openingsReceived: %v", err)
+	}
 	return buf, rem, nil
 }
 
@@ -410,6 +598,10 @@ func (rnger *RNGer) Unmarshal(buf []byte, rem int) ([]byte, int, error) {
 	if err != nil {
 		return buf, rem, fmt.Errorf("unmarshaling opener: %v", err)
 	}
+	buf, rem, err = rnger.openingsReceived.Unmarshal(buf, rem)
+	if err != nil {
+		return buf, rem, fmt.Errorf("unmarshaling openingsReceived: %v", err)
+	}
 	return buf, rem, nil
 }
 
@@ -0,0 +1,163 @@
+package rng
+
+import (
+	"github.com/renproject/secp256k1"
+	"github.com/renproject/shamir"
+
+	"github.com/renproject/mpc/rng/compute"
+)
+
+// CommitmentScheme abstracts over the verifiable secret sharing scheme used
+// to combine BRNG shares into a player's own share and a final reconstructed
+// share. RNGer drives these same steps (local share/commitment accumulation,
+// final share construction) regardless of which scheme is in use, and does
+// not call IsValid on the BRNG shares it is given; only the
+// scheme itself determines whether the result is hiding (Pedersen) or has a
+// publicly recoverable commitment (Feldman).
+type CommitmentScheme interface {
+	// IsValid reports whether share is consistent with commitment.
+	IsValid(commitment *shamir.Commitment, share *shamir.VerifiableShare) bool
+
+	// Parameter returns the scheme's blinding point, used to construct the
+	// underlying Opener. It is the Pedersen parameter H for Pedersen, and the
+	// point at infinity (no blinding) for Feldman.
+	Parameter() secp256k1.Point
+
+	// ShareOfShare locally accumulates the shares destined for index from a
+	// BRNG output, in the same way regardless of scheme; only the resulting
+	// share's decommitment component is scheme-dependent (see Combine).
+	ShareOfShare(index secp256k1.Fn, shares shamir.VerifiableShares) shamir.VerifiableShare
+
+	// ShareCommitment locally accumulates the commitments corresponding to
+	// ShareOfShare's share, evaluated for index.
+	ShareCommitment(index secp256k1.Fn, commitments []shamir.Commitment) *secp256k1.Point
+
+	// Combine constructs the final VerifiableShare for index once secret and
+	// decommitment have been reconstructed by the Opener. Pedersen keeps the
+	// decommitment so that the share remains hiding; Feldman discards it,
+	// since a Feldman commitment's constant term is already a public key.
+	Combine(index, secret, decommitment secp256k1.Fn) shamir.VerifiableShare
+}
+
+// Pedersen is the default CommitmentScheme, in which commitments are
+// blinded with the Pedersen parameter H so that they are hiding as well as
+// binding.
+type Pedersen struct {
+	H secp256k1.Point
+}
+
+// NewPedersen constructs a Pedersen CommitmentScheme with the given
+// commitment scheme parameter.
+func NewPedersen(h secp256k1.Point) Pedersen {
+	return Pedersen{H: h}
+}
+
+// IsValid implements the CommitmentScheme interface.
+func (scheme Pedersen) IsValid(commitment *shamir.Commitment, share *shamir.VerifiableShare) bool {
+	return shamir.IsValid(scheme.H, commitment, share)
+}
+
+// Parameter implements the CommitmentScheme interface.
+func (scheme Pedersen) Parameter() secp256k1.Point {
+	return scheme.H
+}
+
+// ShareOfShare implements the CommitmentScheme interface.
+func (scheme Pedersen) ShareOfShare(index secp256k1.Fn, shares shamir.VerifiableShares) shamir.VerifiableShare {
+	return compute.ShareOfShare(index, shares)
+}
+
+// ShareCommitment implements the CommitmentScheme interface.
+func (scheme Pedersen) ShareCommitment(index secp256k1.Fn, commitments []shamir.Commitment) *secp256k1.Point {
+	return compute.ShareCommitment(index, commitments)
+}
+
+// Combine implements the CommitmentScheme interface, keeping the
+// reconstructed decommitment so that the resulting share stays hiding.
+func (scheme Pedersen) Combine(index, secret, decommitment secp256k1.Fn) shamir.VerifiableShare {
+	return shamir.NewVerifiableShare(shamir.NewShare(index, secret), decommitment)
+}
+
+// Feldman is a non-hiding CommitmentScheme, in which commitments are simply
+// g^{a_i} for each polynomial coefficient a_i, with no blinding polynomial.
+// Feldman shares therefore carry only the value component of a
+// shamir.VerifiableShare; the decommitment is unused and ignored. This is
+// the form downstream threshold-signature protocols such as FROST require,
+// since they need the constant term's commitment g^{sk} to be recoverable,
+// which a Pedersen commitment deliberately hides.
+type Feldman struct{}
+
+// NewFeldman constructs a Feldman CommitmentScheme.
+func NewFeldman() Feldman {
+	return Feldman{}
+}
+
+// IsValid implements the CommitmentScheme interface. It checks that
+// share.Value()*G is equal to the commitment evaluated at the share's index,
+// ignoring the decommitment entirely.
+func (scheme Feldman) IsValid(commitment *shamir.Commitment, share *shamir.VerifiableShare) bool {
+	var expected secp256k1.Point
+	expected.BaseExp(&share.Share.Value)
+
+	actual := Eval(*commitment, share.Share.Index)
+
+	return expected.Eq(&actual)
+}
+
+// Parameter implements the CommitmentScheme interface. Feldman has no
+// blinding polynomial, so the point at infinity is used as the Opener's
+// parameter; it never contributes to a Feldman commitment.
+func (scheme Feldman) Parameter() secp256k1.Point {
+	return secp256k1.NewPointInfinity()
+}
+
+// ShareOfShare implements the CommitmentScheme interface. The accumulation
+// is identical to Pedersen's; only the decommitment component that Combine
+// goes on to drop differs between the two schemes.
+func (scheme Feldman) ShareOfShare(index secp256k1.Fn, shares shamir.VerifiableShares) shamir.VerifiableShare {
+	return compute.ShareOfShare(index, shares)
+}
+
+// ShareCommitment implements the CommitmentScheme interface.
+func (scheme Feldman) ShareCommitment(index secp256k1.Fn, commitments []shamir.Commitment) *secp256k1.Point {
+	return compute.ShareCommitment(index, commitments)
+}
+
+// Combine implements the CommitmentScheme interface, discarding the
+// reconstructed decommitment entirely so that the resulting share's
+// commitment is just g^{secret}, recoverable without needing the blinding
+// term.
+func (scheme Feldman) Combine(index, secret, decommitment secp256k1.Fn) shamir.VerifiableShare {
+	return shamir.NewVerifiableShare(shamir.NewShare(index, secret), secp256k1.NewFnFromU32(0))
+}
+
+// Eval evaluates a polynomial commitment at x, returning
+// sum_j x^j * commitment[j]. This is the same evaluation used internally by
+// shamir's own VSS checker, exposed here so that callers running a
+// higher-level protocol on top of RNG (such as a DKG) can extract the
+// underlying per-coefficient group elements; see Coefficients.
+func Eval(commitment shamir.Commitment, x secp256k1.Fn) secp256k1.Point {
+	coeffs := Coefficients(commitment)
+
+	var result secp256k1.Point
+	result = secp256k1.NewPointInfinity()
+
+	power := secp256k1.NewFnFromU32(1)
+	for _, c := range coeffs {
+		var term secp256k1.Point
+		term.Scale(&c, &power)
+		result.Add(&result, &term)
+		power.Mul(&power, &x)
+	}
+
+	return result
+}
+
+// Coefficients returns the per-coefficient group elements that back the
+// given commitment, analogous to CIRCL's polynomial.Coefficients. This is
+// the accessor a caller running a higher-level protocol (e.g. a DKG built on
+// top of RNG) needs in order to extract individual coefficient commitments
+// rather than just the aggregate.
+func Coefficients(commitment shamir.Commitment) []secp256k1.Point {
+	return []secp256k1.Point(commitment)
+}
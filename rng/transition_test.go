@@ -67,19 +67,19 @@ var _ = Describe("RNG/RZG state transitions", func() {
 		return n, indices, otherIndices, index, b, k, h
 	}
 
-	TransitionToWaitingOpen := func(rnger *rng.RNGer, isZero bool) (
+	TransitionToWaitingOpen := func(rnger *rng.RNGer, scheme rng.CommitmentScheme, isZero bool) (
 		[]shamir.VerifiableShares,
 		[][]shamir.Commitment,
 		map[secp256k1.Fn]shamir.VerifiableShares,
 	) {
 		ownSetsOfShares, ownSetsOfCommitments, openingsByPlayer, _ :=
 			rngutil.RNGSharesBatch(indices, index, b, k, h, isZero)
-		_, *rnger, _, _ = rng.New(index, indices, uint32(b), uint32(k), h, ownSetsOfShares, ownSetsOfCommitments, isZero)
+		_, *rnger, _, _ = rng.New(index, indices, uint32(b), uint32(k), scheme, ownSetsOfShares, ownSetsOfCommitments, isZero)
 
 		return ownSetsOfShares, ownSetsOfCommitments, openingsByPlayer
 	}
 
-	TransitionToDone := func(rnger *rng.RNGer, isZero bool) (
+	TransitionToDone := func(rnger *rng.RNGer, scheme rng.CommitmentScheme, isZero bool) (
 		[]shamir.VerifiableShares,
 		[][]shamir.Commitment,
 		map[secp256k1.Fn]shamir.VerifiableShares,
@@ -89,16 +89,28 @@ var _ = Describe("RNG/RZG state transitions", func() {
 		ownSetsOfShares, ownSetsOfCommitments, openingsByPlayer, _ :=
 			rngutil.RNGSharesBatch(indices, index, b, k, h, isZero)
 		var commitments []shamir.Commitment
-		_, *rnger, _, commitments = rng.New(index, indices, uint32(b), uint32(k), h, ownSetsOfShares, ownSetsOfCommitments, isZero)
+		_, *rnger, _, commitments = rng.New(index, indices, uint32(b), uint32(k), scheme, ownSetsOfShares, ownSetsOfCommitments, isZero)
 
 		var shares shamir.VerifiableShares
 		for _, from := range otherIndices[:k-1] {
-			_, shares = rnger.TransitionOpen(openingsByPlayer[from])
+			_, shares = rnger.TransitionOpen(from, openingsByPlayer[from])
 		}
 
 		return ownSetsOfShares, ownSetsOfCommitments, openingsByPlayer, shares, commitments
 	}
 
+	// expectSharesValidAgainstCommitments asserts that every unlocked share is
+	// still consistent with the commitment it was originally issued against,
+	// i.e. that Abort did not hand back shares that have somehow been
+	// invalidated by the transitions that preceded it.
+	expectSharesValidAgainstCommitments := func(scheme rng.CommitmentScheme, setsOfShares []shamir.VerifiableShares, setsOfCommitments [][]shamir.Commitment) {
+		for i, shares := range setsOfShares {
+			for j, share := range shares {
+				Expect(scheme.IsValid(&setsOfCommitments[i][j], &share)).To(BeTrue())
+			}
+		}
+	}
+
 	BeforeEach(func() {
 		n, indices, otherIndices, index, b, k, h = Setup()
 	})
@@ -122,7 +134,7 @@ var _ = Describe("RNG/RZG state transitions", func() {
 
 			/*
 				Specify("state machine initialisation", func() {
-					event, rnger := rng.New(index, indices, uint32(b), uint32(k), h, nil, nil, false)
+					event, rnger := rng.New(index, indices, uint32(b), uint32(k), rng.NewPedersen(h), nil, nil, false)
 
 					Expect(event).To(Equal(rng.Initialised))
 					Expect(rnger.State()).To(Equal(rng.Init))
@@ -137,148 +149,272 @@ var _ = Describe("RNG/RZG state transitions", func() {
 				})
 			*/
 
-			Context("Init state transitions", func() {
-				Specify("valid BRNG shares and commitments -> WaitingOpen", func() {
-					setsOfShares, setsOfCommitments := rngutil.BRNGOutputBatch(index, b, c, h)
-					event, _, directedOpenings, _ := rng.New(index, indices, uint32(b), uint32(k), h, setsOfShares, setsOfCommitments, isZero)
-
-					Expect(event).To(Equal(rng.SharesConstructed))
-
-					// With valid shares, the shares for the directed opens
-					// should be computed.
-					for _, j := range indices {
-						shares := directedOpenings[j]
-						for _, share := range shares {
-							Expect(share).ToNot(Equal(shamir.VerifiableShares{}))
-						}
-					}
-				})
-
-				Specify("empty sets of shares and valid commitments -> WaitingOpen", func() {
-					_, setsOfCommitments := rngutil.BRNGOutputBatch(index, b, c, h)
-					event, _, directedOpenings, _ := rng.New(index, indices, uint32(b), uint32(k), h, []shamir.VerifiableShares{}, setsOfCommitments, isZero)
-
-					Expect(event).To(Equal(rng.CommitmentsConstructed))
-
-					// With empty shares, the shares for the directed opens
-					// should not be computed.
-					for _, j := range indices {
-						shares := directedOpenings[j]
-						for _, share := range shares {
-							Expect(share).To(Equal(shamir.VerifiableShares{}))
-						}
-					}
-				})
-
-				Specify("shares with incorrect batch size -> WaitingOpen", func() {
-					setsOfShares, setsOfCommitments := rngutil.BRNGOutputBatch(index, b, c, h)
-					event, _, directedOpenings, _ := rng.New(index, indices, uint32(b), uint32(k), h, setsOfShares[1:], setsOfCommitments, isZero)
-
-					Expect(event).To(Equal(rng.CommitmentsConstructed))
-
-					// With invalid shares, the shares for the directed opens
-					// should not be computed.
-					for _, j := range indices {
-						shares := directedOpenings[j]
-						for _, share := range shares {
-							Expect(share).To(Equal(shamir.VerifiableShares{}))
-						}
-					}
-				})
-
-				Specify("shares with incorrect threshold size -> panic", func() {
-					setsOfShares, setsOfCommitments := rngutil.BRNGOutputBatch(index, b, c, h)
-
-					// Make the number of shares be incorrect.
-					setsOfShares[0] = setsOfShares[0][1:]
-					Expect(func() {
-						_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), h, setsOfShares, setsOfCommitments, isZero)
-					}).To(Panic())
-				})
-
-				Specify("invalid commitments -> panic", func() {
-					setsOfShares, setsOfCommitments := rngutil.BRNGOutputBatch(index, b, c, h)
-
-					// Incorrect batch length.
-					j := rand.Intn(b)
-					wrongBatch := setsOfCommitments
-					wrongBatch = append(wrongBatch[:j], wrongBatch[j+1:]...)
-					Expect(func() {
-						_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), h, setsOfShares, wrongBatch, isZero)
-					}).To(Panic())
-					Expect(func() {
-						_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), h, []shamir.VerifiableShares{}, wrongBatch, isZero)
-					}).To(Panic())
-
-					// Incorrect threshold.
-					j = rand.Intn(c)
-					wrongK := setsOfCommitments
-					wrongK[0] = append(wrongK[0][:j], wrongK[0][j+1:]...)
-					Expect(func() {
-						_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), h, setsOfShares, wrongK, isZero)
-					}).To(Panic())
-					Expect(func() {
-						_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), h, []shamir.VerifiableShares{}, wrongK, isZero)
-					}).To(Panic())
-				})
-			})
-
-			Context("WaitingOpen state transitions", func() {
-				var rnger rng.RNGer
-				var openingsByPlayer map[secp256k1.Fn]shamir.VerifiableShares
-
-				JustBeforeEach(func() {
-					_, _, openingsByPlayer = TransitionToWaitingOpen(&rnger, isZero)
-				})
-
-				Specify("invalid directed opening -> do nothing", func() {
-					from := otherIndices[rand.Intn(len(otherIndices))]
-
-					// Openings length not equal to batch size
-					event, _ := rnger.TransitionOpen(openingsByPlayer[from][1:])
-
-					Expect(event).To(Equal(rng.OpeningsIgnored))
-
-					// Sender index is randomly chosen, so does not exist in
-					// the initial player indices
-					shamirutil.PerturbIndex(&openingsByPlayer[from][rand.Intn(b)])
-					event, _ = rnger.TransitionOpen(openingsByPlayer[from])
-
-					Expect(event).To(Equal(rng.OpeningsIgnored))
-				})
-
-				Specify("directed opening (not yet k) -> WaitingOpen", func() {
-					from := otherIndices[rand.Intn(len(otherIndices))]
-					event, _ := rnger.TransitionOpen(openingsByPlayer[from])
-
-					Expect(event).To(Equal(rng.OpeningsAdded))
-				})
-
-				Specify("kth directed open -> Done", func() {
-					for i, from := range otherIndices {
-						// The own player's openings have already been
-						// processed.
-						count := i + 1
-
-						event, shares := rnger.TransitionOpen(openingsByPlayer[from])
-
-						if count == k-1 {
-							Expect(event).To(Equal(rng.RNGsReconstructed))
-							Expect(len(shares)).To(Equal(b))
-							break
-						}
+			// The whole suite below is run once per CommitmentScheme, so
+			// that Feldman gets the same state-machine coverage as the
+			// default Pedersen scheme, not just a shallow smoke test.
+			// fixture builds the Init-state BRNG dealer output used to drive
+			// the scheme directly, so that Feldman is exercised against
+			// genuinely unblinded (g^{a_i}-only) commitments and shares rather
+			// than rngutil's Pedersen-blinded ones.
+			schemes := []struct {
+				name    string
+				build   func() rng.CommitmentScheme
+				fixture func(index secp256k1.Fn, b, c, k int, h secp256k1.Point) ([]shamir.VerifiableShares, [][]shamir.Commitment)
+			}{
+				{"Pedersen", func() rng.CommitmentScheme { return rng.NewPedersen(h) }, func(index secp256k1.Fn, b, c, k int, h secp256k1.Point) ([]shamir.VerifiableShares, [][]shamir.Commitment) {
+					return rngutil.BRNGOutputBatch(index, b, c, h)
+				}},
+				{"Feldman", func() rng.CommitmentScheme { return rng.NewFeldman() }, feldmanBRNGOutputBatch},
+			}
 
-						Expect(event).To(Equal(rng.OpeningsAdded))
-					}
+			for _, s := range schemes {
+				s := s
+
+				Context(s.name, func() {
+					var scheme rng.CommitmentScheme
+
+					BeforeEach(func() {
+						scheme = s.build()
+					})
+
+					Context("Init state transitions", func() {
+						Specify("valid BRNG shares and commitments -> WaitingOpen", func() {
+							setsOfShares, setsOfCommitments := s.fixture(index, b, c, k, h)
+							event, rnger, directedOpenings, commitments := rng.New(index, indices, uint32(b), uint32(k), scheme, setsOfShares, setsOfCommitments, isZero)
+
+							Expect(event).To(Equal(rng.SharesConstructed))
+							Expect(rnger.Scheme()).To(Equal(scheme))
+
+							// With valid shares, the shares for the directed opens
+							// should be computed, and every one of them should be
+							// valid under this scheme against the share commitment
+							// its recipient j accumulates (ShareCommitment for j,
+							// scaled by j for RZG), as the recipient's Opener checks
+							// it - this is the check that would fail if the
+							// dealer-level fixture were shaped for a different
+							// scheme (e.g. Pedersen-blinded shares run through
+							// Feldman's unblinded validity check).
+							for _, j := range indices {
+								j := j
+								shares := directedOpenings[j]
+								for i, share := range shares {
+									Expect(share).ToNot(Equal(shamir.VerifiableShares{}))
+									shareCommitment := scheme.ShareCommitment(j, setsOfCommitments[i])
+									if isZero {
+										shareCommitment.Scale(shareCommitment, &j)
+									}
+									var recipientCommitment shamir.Commitment
+									recipientCommitment.Set(shareCommitment)
+									Expect(scheme.IsValid(&recipientCommitment, &share)).To(BeTrue())
+								}
+							}
+						})
+
+						Specify("empty sets of shares and valid commitments -> WaitingOpen", func() {
+							_, setsOfCommitments := s.fixture(index, b, c, k, h)
+							event, _, directedOpenings, _ := rng.New(index, indices, uint32(b), uint32(k), scheme, []shamir.VerifiableShares{}, setsOfCommitments, isZero)
+
+							Expect(event).To(Equal(rng.CommitmentsConstructed))
+
+							// With empty shares, the shares for the directed opens
+							// should not be computed.
+							for _, j := range indices {
+								shares := directedOpenings[j]
+								for _, share := range shares {
+									Expect(share).To(Equal(shamir.VerifiableShares{}))
+								}
+							}
+						})
+
+						Specify("shares with incorrect batch size -> WaitingOpen", func() {
+							setsOfShares, setsOfCommitments := s.fixture(index, b, c, k, h)
+							event, _, directedOpenings, _ := rng.New(index, indices, uint32(b), uint32(k), scheme, setsOfShares[1:], setsOfCommitments, isZero)
+
+							Expect(event).To(Equal(rng.CommitmentsConstructed))
+
+							// With invalid shares, the shares for the directed opens
+							// should not be computed.
+							for _, j := range indices {
+								shares := directedOpenings[j]
+								for _, share := range shares {
+									Expect(share).To(Equal(shamir.VerifiableShares{}))
+								}
+							}
+						})
+
+						Specify("shares with incorrect threshold size -> panic", func() {
+							setsOfShares, setsOfCommitments := s.fixture(index, b, c, k, h)
+
+							// Make the number of shares be incorrect.
+							setsOfShares[0] = setsOfShares[0][1:]
+							Expect(func() {
+								_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), scheme, setsOfShares, setsOfCommitments, isZero)
+							}).To(Panic())
+						})
+
+						Specify("invalid commitments -> panic", func() {
+							setsOfShares, setsOfCommitments := s.fixture(index, b, c, k, h)
+
+							// Incorrect batch length.
+							j := rand.Intn(b)
+							wrongBatch := setsOfCommitments
+							wrongBatch = append(wrongBatch[:j], wrongBatch[j+1:]...)
+							Expect(func() {
+								_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), scheme, setsOfShares, wrongBatch, isZero)
+							}).To(Panic())
+							Expect(func() {
+								_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), scheme, []shamir.VerifiableShares{}, wrongBatch, isZero)
+							}).To(Panic())
+
+							// Incorrect threshold.
+							j = rand.Intn(c)
+							wrongK := setsOfCommitments
+							wrongK[0] = append(wrongK[0][:j], wrongK[0][j+1:]...)
+							Expect(func() {
+								_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), scheme, setsOfShares, wrongK, isZero)
+							}).To(Panic())
+							Expect(func() {
+								_, _, _, _ = rng.New(index, indices, uint32(b), uint32(k), scheme, []shamir.VerifiableShares{}, wrongK, isZero)
+							}).To(Panic())
+						})
+					})
+
+					Context("WaitingOpen state transitions", func() {
+						var rnger rng.RNGer
+						var openingsByPlayer map[secp256k1.Fn]shamir.VerifiableShares
+
+						JustBeforeEach(func() {
+							_, _, openingsByPlayer = TransitionToWaitingOpen(&rnger, scheme, isZero)
+						})
+
+						Specify("invalid directed opening -> do nothing", func() {
+							from := otherIndices[rand.Intn(len(otherIndices))]
+
+							// Openings length not equal to batch size
+							event, _ := rnger.TransitionOpen(from, openingsByPlayer[from][1:])
+
+							Expect(event).To(Equal(rng.OpeningsIgnored))
+
+							// Sender index is randomly chosen, so does not exist in
+							// the initial player indices
+							shamirutil.PerturbIndex(&openingsByPlayer[from][rand.Intn(b)])
+							event, _ = rnger.TransitionOpen(from, openingsByPlayer[from])
+
+							Expect(event).To(Equal(rng.OpeningsIgnored))
+						})
+
+						Specify("directed opening (not yet k) -> WaitingOpen", func() {
+							from := otherIndices[rand.Intn(len(otherIndices))]
+							event, _ := rnger.TransitionOpen(from, openingsByPlayer[from])
+
+							Expect(event).To(Equal(rng.OpeningsAdded))
+						})
+
+						Specify("kth directed open -> Done", func() {
+							for i, from := range otherIndices {
+								// The own player's openings have already been
+								// processed.
+								count := i + 1
+
+								event, shares := rnger.TransitionOpen(from, openingsByPlayer[from])
+
+								if count == k-1 {
+									Expect(event).To(Equal(rng.RNGsReconstructed))
+									Expect(len(shares)).To(Equal(b))
+									Expect(rnger.ReceivedOpenings().Count()).To(Equal(k))
+									Expect(rnger.MissingOpenings().Count()).To(Equal(n - k))
+									break
+								}
+
+								Expect(event).To(Equal(rng.OpeningsAdded))
+							}
+						})
+
+						Specify("duplicated directed opening does not double-count", func() {
+							before := rnger.ReceivedOpenings().Count()
+							from := otherIndices[rand.Intn(len(otherIndices))]
+
+							event, _ := rnger.TransitionOpen(from, openingsByPlayer[from])
+							Expect(event).To(Equal(rng.OpeningsAdded))
+							Expect(rnger.ReceivedOpenings().Count()).To(Equal(before + 1))
+
+							// Sending the same opening again from the same player
+							// should not advance the bitmap any further, even though
+							// the opener itself may ignore or re-add the share.
+							rnger.TransitionOpen(from, openingsByPlayer[from])
+							Expect(rnger.ReceivedOpenings().Count()).To(Equal(before + 1))
+						})
+					})
+
+					Context("Abort", func() {
+						Specify("abort right after construction unlocks the consumed shares", func() {
+							ownSetsOfShares, ownSetsOfCommitments, _, _ := rngutil.RNGSharesBatch(indices, index, b, k, h, isZero)
+							_, rnger, _, _ := rng.New(index, indices, uint32(b), uint32(k), scheme, ownSetsOfShares, ownSetsOfCommitments, isZero)
+
+							event, unlocked := rnger.Abort()
+
+							Expect(event).To(Equal(rng.RNGAborted))
+							Expect(unlocked).To(Equal(ownSetsOfShares))
+							// The raw BRNG-level fixture shares/commitments are
+							// always in Pedersen form regardless of which
+							// scheme the RNGer itself was built with.
+							expectSharesValidAgainstCommitments(rng.NewPedersen(h), unlocked, ownSetsOfCommitments)
+						})
+
+						Specify("abort from WaitingOpen with some openings received", func() {
+							var rnger rng.RNGer
+							ownSetsOfShares, ownSetsOfCommitments, openingsByPlayer := TransitionToWaitingOpen(&rnger, scheme, isZero)
+
+							from := otherIndices[rand.Intn(len(otherIndices))]
+							rnger.TransitionOpen(from, openingsByPlayer[from])
+
+							event, unlocked := rnger.Abort()
+
+							Expect(event).To(Equal(rng.RNGAborted))
+							Expect(unlocked).To(Equal(ownSetsOfShares))
+							Expect(rnger.ReceivedOpenings().Count()).To(Equal(0))
+							expectSharesValidAgainstCommitments(rng.NewPedersen(h), unlocked, ownSetsOfCommitments)
+						})
+
+						Specify("abort from Done unlocks the shares that were consumed on construction", func() {
+							rnger := rng.RNGer{}
+							ownSetsOfShares, ownSetsOfCommitments, _, _, _ := TransitionToDone(&rnger, scheme, isZero)
+
+							event, unlocked := rnger.Abort()
+
+							Expect(event).To(Equal(rng.RNGAborted))
+							Expect(unlocked).To(Equal(ownSetsOfShares))
+							Expect(rnger.ReceivedOpenings().Count()).To(Equal(0))
+							expectSharesValidAgainstCommitments(rng.NewPedersen(h), unlocked, ownSetsOfCommitments)
+						})
+					})
+
+					Context("Deadline", func() {
+						Specify("a deadline-bound RNGer reports expiry relative to now", func() {
+							ownSetsOfShares, ownSetsOfCommitments, _, _ := rngutil.RNGSharesBatch(indices, index, b, k, h, isZero)
+							deadline := time.Now().Add(time.Hour)
+							_, rnger, _, _ := rng.NewWithDeadline(index, indices, uint32(b), uint32(k), scheme, ownSetsOfShares, ownSetsOfCommitments, isZero, deadline)
+
+							Expect(rnger.Expired(time.Now())).ToNot(BeTrue())
+							Expect(rnger.Expired(deadline.Add(time.Second))).To(BeTrue())
+						})
+
+						Specify("an RNGer without a deadline never expires", func() {
+							ownSetsOfShares, ownSetsOfCommitments, _, _ := rngutil.RNGSharesBatch(indices, index, b, k, h, isZero)
+							_, rnger, _, _ := rng.New(index, indices, uint32(b), uint32(k), scheme, ownSetsOfShares, ownSetsOfCommitments, isZero)
+
+							Expect(rnger.Expired(time.Now().Add(24 * time.Hour))).ToNot(BeTrue())
+						})
+					})
 				})
-			})
+			}
 		})
 
 		Context("Computations", func() {
 			It("should correctly compute the shares and commitments", func() {
 				ownSetsOfShares, ownSetsOfCommitments, openingsByPlayer, _ :=
 					rngutil.RNGSharesBatch(indices, index, b, k, h, isZero)
-				_, _, directedOpenings, _ := rng.New(index, indices, uint32(b), uint32(k), h, ownSetsOfShares, ownSetsOfCommitments, isZero)
+				_, _, directedOpenings, _ := rng.New(index, indices, uint32(b), uint32(k), rng.NewPedersen(h), ownSetsOfShares, ownSetsOfCommitments, isZero)
 
 				selfOpenings := directedOpenings[index]
 				for i, share := range selfOpenings {
@@ -288,7 +424,7 @@ var _ = Describe("RNG/RZG state transitions", func() {
 
 			It("should compute valid shares and commitments for the random number", func() {
 				rnger := rng.RNGer{}
-				_, _, _, shares, commitments := TransitionToDone(&rnger, isZero)
+				_, _, _, shares, commitments := TransitionToDone(&rnger, rng.NewPedersen(h), isZero)
 
 				// The reconstructed verifiable shares of the batch of unbiased
 				// random numbers should be valid against the commitments for
@@ -300,3 +436,44 @@ var _ = Describe("RNG/RZG state transitions", func() {
 		})
 	}
 })
+
+// feldmanBRNGOutputBatch builds b sets of c independent Feldman-shaped BRNG
+// dealer contributions for index: each dealer's commitment is unblinded
+// (g^{a_j} per coefficient, no h term) and each share is an honest
+// degree-(k-1) polynomial evaluation, matching the CommitmentScheme.fixture
+// signature so it can stand in for rngutil.BRNGOutputBatch, which is
+// Pedersen-blinded and so cannot exercise Feldman's unblinded validity check.
+func feldmanBRNGOutputBatch(index secp256k1.Fn, b, c, k int, _ secp256k1.Point) ([]shamir.VerifiableShares, [][]shamir.Commitment) {
+	shares := make([]shamir.VerifiableShares, b)
+	coms := make([][]shamir.Commitment, b)
+
+	for i := 0; i < b; i++ {
+		shares[i] = make(shamir.VerifiableShares, c)
+		coms[i] = make([]shamir.Commitment, c)
+
+		for j := 0; j < c; j++ {
+			coeffs := make([]secp256k1.Fn, k)
+			for l := range coeffs {
+				coeffs[l] = secp256k1.RandomFn()
+			}
+
+			commitment := shamir.NewCommitmentWithCapacity(k)
+			for _, coeff := range coeffs {
+				var g secp256k1.Point
+				g.BaseExp(&coeff)
+				commitment.Append(g)
+			}
+			coms[i][j] = commitment
+
+			value := coeffs[k-1]
+			for l := k - 2; l >= 0; l-- {
+				value.Mul(&value, &index)
+				value.Add(&value, &coeffs[l])
+			}
+
+			shares[i][j] = shamir.NewVerifiableShare(shamir.NewShare(index, value), secp256k1.NewFnFromU32(0))
+		}
+	}
+
+	return shares, coms
+}
@@ -0,0 +1,248 @@
+// Package tsig implements a two-round, FROST-style threshold Schnorr
+// signature on top of the existing RNGer/BRNGer machinery: a long-lived
+// shared signing key (e.g. produced by BRNG's DKG mode) plus a batch of
+// nonces produced by RNGer are combined to jointly sign a message without
+// ever reconstructing the private key.
+package tsig
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"sort"
+
+	"github.com/renproject/secp256k1"
+	"github.com/renproject/shamir"
+)
+
+// Nonce is a signer's pair of Pedersen-committed nonce shares (d, e), as
+// produced by a batch-of-2 invocation of rng.RNGer.
+type Nonce struct {
+	D, E shamir.VerifiableShare
+}
+
+// Commitment is the public commitment pair (D = d.G, E = e.G) a signer
+// publishes for a given nonce, computed from the signer's nonce share values.
+type Commitment struct {
+	D, E secp256k1.Point
+}
+
+// Signer drives a single threshold Schnorr signing session for one message.
+// A batch invocation simply runs b independent Signers, one per message,
+// matching the batchSize shape of RNGer.
+type Signer struct {
+	index   secp256k1.Fn
+	indices []secp256k1.Fn
+
+	message []byte
+
+	// sk is this signer's VSS share of the long-lived group signing key.
+	sk shamir.VerifiableShare
+	// pk is the group's public key.
+	pk secp256k1.Point
+
+	nonce Nonce
+}
+
+// New constructs a Signer for a single message, given this signer's VSS
+// share of the group key, the group's public key, and this signer's nonce
+// pair.
+func New(
+	index secp256k1.Fn,
+	indices []secp256k1.Fn,
+	sk shamir.VerifiableShare,
+	pk secp256k1.Point,
+	nonce Nonce,
+	message []byte,
+) Signer {
+	return Signer{
+		index:   index,
+		indices: indices,
+		message: message,
+		sk:      sk,
+		pk:      pk,
+		nonce:   nonce,
+	}
+}
+
+// Commitment returns this signer's public nonce commitment pair
+// (D_i, E_i) = (d_i.G, e_i.G), computed by base-point multiplication of this
+// signer's nonce share values; the commitments produced when the nonce batch
+// was constructed are not used.
+func (s Signer) Commitment() Commitment {
+	var d, e secp256k1.Point
+	d.BaseExp(&s.nonce.D.Share.Value)
+	e.BaseExp(&s.nonce.E.Share.Value)
+	return Commitment{D: d, E: e}
+}
+
+// bindingFactor computes rho_i = H(i, m, {D_j, E_j}) for this signer, binding
+// its contribution to the message and the full set of published nonce
+// commitments so that nonces cannot be reused across sessions.
+func bindingFactor(index secp256k1.Fn, message []byte, commitments map[secp256k1.Fn]Commitment) secp256k1.Fn {
+	h := sha256.New()
+
+	var indexBytes [32]byte
+	index.PutB32(indexBytes[:])
+	h.Write(indexBytes[:])
+	h.Write(message)
+
+	for _, j := range sortedIndices(commitments) {
+		c := commitments[j]
+		var dBytes, eBytes [32]byte
+		c.D.PutB32(dBytes[:])
+		c.E.PutB32(eBytes[:])
+		h.Write(dBytes[:])
+		h.Write(eBytes[:])
+	}
+
+	var rho secp256k1.Fn
+	digest := h.Sum(nil)
+	rho.SetB32(digest)
+	return rho
+}
+
+// groupCommitment computes R = sum_j (D_j + rho_j . E_j) over the signing
+// set.
+func groupCommitment(message []byte, commitments map[secp256k1.Fn]Commitment) secp256k1.Point {
+	var r secp256k1.Point
+	r = secp256k1.NewPointInfinity()
+
+	for _, j := range sortedIndices(commitments) {
+		c := commitments[j]
+		rho := bindingFactor(j, message, commitments)
+
+		var rhoE secp256k1.Point
+		rhoE.Scale(&c.E, &rho)
+
+		var term secp256k1.Point
+		term.Add(&c.D, &rhoE)
+
+		r.Add(&r, &term)
+	}
+
+	return r
+}
+
+// challenge computes c = H(R, PK, m), the Schnorr challenge shared by every
+// signer.
+func challenge(r, pk secp256k1.Point, message []byte) secp256k1.Fn {
+	h := sha256.New()
+
+	var rBytes, pkBytes [32]byte
+	r.PutB32(rBytes[:])
+	pk.PutB32(pkBytes[:])
+	h.Write(rBytes[:])
+	h.Write(pkBytes[:])
+	h.Write(message)
+
+	var c secp256k1.Fn
+	c.SetB32(h.Sum(nil))
+	return c
+}
+
+// lagrangeCoefficient computes lambda_i, the Lagrange coefficient for index
+// i within the signing set indices, evaluated at x=0.
+func lagrangeCoefficient(index secp256k1.Fn, indices []secp256k1.Fn) secp256k1.Fn {
+	num := secp256k1.NewFnFromU32(1)
+	den := secp256k1.NewFnFromU32(1)
+
+	for _, j := range indices {
+		if j.Eq(&index) {
+			continue
+		}
+
+		num.Mul(&num, &j)
+
+		var diff secp256k1.Fn
+		diff.Sub(&j, &index)
+		den.Mul(&den, &diff)
+	}
+
+	var inv secp256k1.Fn
+	inv.Inverse(&den)
+
+	var lambda secp256k1.Fn
+	lambda.Mul(&num, &inv)
+	return lambda
+}
+
+// Sign computes this signer's contribution z_i = d_i + e_i.rho_i +
+// lambda_i.s_i.c, given the full set of published nonce commitments for the
+// signing round.
+func (s Signer) Sign(commitments map[secp256k1.Fn]Commitment) secp256k1.Fn {
+	rho := bindingFactor(s.index, s.message, commitments)
+	r := groupCommitment(s.message, commitments)
+	c := challenge(r, s.pk, s.message)
+	lambda := lagrangeCoefficient(s.index, s.indices)
+
+	var eRho secp256k1.Fn
+	eRho.Mul(&s.nonce.E.Share.Value, &rho)
+
+	var lambdaSkC secp256k1.Fn
+	lambdaSkC.Mul(&lambda, &s.sk.Share.Value)
+	lambdaSkC.Mul(&lambdaSkC, &c)
+
+	var z secp256k1.Fn
+	z.Add(&s.nonce.D.Share.Value, &eRho)
+	z.Add(&z, &lambdaSkC)
+
+	return z
+}
+
+// Signature is the final aggregated threshold Schnorr signature.
+type Signature struct {
+	R secp256k1.Point
+	Z secp256k1.Fn
+}
+
+// Aggregate combines each signer's z_i contribution into the final
+// signature (R, z), reusing the same group commitment R that every signer
+// computed locally.
+func Aggregate(message []byte, pk secp256k1.Point, commitments map[secp256k1.Fn]Commitment, zs map[secp256k1.Fn]secp256k1.Fn) Signature {
+	r := groupCommitment(message, commitments)
+
+	z := secp256k1.NewFnFromU32(0)
+	for _, j := range sortedIndices(commitments) {
+		zj := zs[j]
+		z.Add(&z, &zj)
+	}
+
+	return Signature{R: r, Z: z}
+}
+
+// Verify reports whether sig is a valid threshold Schnorr signature over
+// message under the group public key pk, checking the standard Schnorr
+// identity z.G == R + c.PK.
+func Verify(pk secp256k1.Point, message []byte, sig Signature) bool {
+	c := challenge(sig.R, pk, message)
+
+	var zG secp256k1.Point
+	zG.BaseExp(&sig.Z)
+
+	var cPK secp256k1.Point
+	cPK.Scale(&pk, &c)
+
+	var expected secp256k1.Point
+	expected.Add(&sig.R, &cPK)
+
+	return zG.Eq(&expected)
+}
+
+// sortedIndices returns the keys of a Commitment map in a deterministic
+// order, so that every signer derives the same binding factors and group
+// commitment regardless of map iteration order.
+func sortedIndices(commitments map[secp256k1.Fn]Commitment) []secp256k1.Fn {
+	indices := make([]secp256k1.Fn, 0, len(commitments))
+	for index := range commitments {
+		indices = append(indices, index)
+	}
+
+	sort.Slice(indices, func(i, j int) bool {
+		var a, b [32]byte
+		indices[i].PutB32(a[:])
+		indices[j].PutB32(b[:])
+		return bytes.Compare(a[:], b[:]) < 0
+	})
+
+	return indices
+}
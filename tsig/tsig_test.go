@@ -0,0 +1,76 @@
+package tsig_test
+
+import (
+	"math/rand"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/renproject/secp256k1"
+	"github.com/renproject/shamir"
+	"github.com/renproject/shamir/shamirutil"
+
+	"github.com/renproject/mpc/tsig"
+)
+
+var _ = Describe("Threshold Schnorr", func() {
+	rand.Seed(int64(time.Now().Nanosecond()))
+
+	Specify("an aggregated signature over a genuinely shared key verifies", func() {
+		k := 3 + rand.Intn(4)
+		indices := shamirutil.RandomIndices(k)
+		message := []byte("hello, threshold schnorr")
+
+		// Build a genuine (k-of-k, for this signing quorum) Shamir sharing of
+		// the group signing key, so that pk is actually sk.G for the sk these
+		// signers hold shares of.
+		secret := secp256k1.RandomFn()
+		coeffs := make([]secp256k1.Fn, k)
+		coeffs[0] = secret
+		for c := 1; c < k; c++ {
+			coeffs[c] = secp256k1.RandomFn()
+		}
+
+		var pk secp256k1.Point
+		pk.BaseExp(&secret)
+
+		signers := make([]tsig.Signer, k)
+		commitments := make(map[secp256k1.Fn]tsig.Commitment, k)
+
+		for i, index := range indices {
+			sk := shamir.VerifiableShare{}
+			sk.Share.Index = index
+			sk.Share.Value = evalPoly(coeffs, index)
+
+			nonce := tsig.Nonce{}
+			nonce.D.Share.Index = index
+			nonce.D.Share.Value = secp256k1.RandomFn()
+			nonce.E.Share.Index = index
+			nonce.E.Share.Value = secp256k1.RandomFn()
+
+			signers[i] = tsig.New(index, indices, sk, pk, nonce, message)
+			commitments[index] = signers[i].Commitment()
+		}
+
+		zs := make(map[secp256k1.Fn]secp256k1.Fn, k)
+		for i, s := range signers {
+			zs[indices[i]] = s.Sign(commitments)
+		}
+
+		sig := tsig.Aggregate(message, pk, commitments, zs)
+
+		Expect(tsig.Verify(pk, message, sig)).To(BeTrue())
+	})
+})
+
+// evalPoly evaluates the polynomial with the given coefficients (lowest
+// degree first) at x, using Horner's method.
+func evalPoly(coeffs []secp256k1.Fn, x secp256k1.Fn) secp256k1.Fn {
+	result := coeffs[len(coeffs)-1]
+	for i := len(coeffs) - 2; i >= 0; i-- {
+		result.Mul(&result, &x)
+		result.Add(&result, &coeffs[i])
+	}
+	return result
+}